@@ -0,0 +1,338 @@
+package mech
+
+import (
+   "bytes"
+   "errors"
+   "github.com/89z/mech/verify"
+   "github.com/edgeware/mp4ff/mp4"
+   "net/url"
+   "path"
+   "strconv"
+   "strings"
+   "time"
+)
+
+// signals that mark a segment as part of an ad break
+const (
+   ad_cue = "cue" // EXT-X-CUE-OUT to EXT-X-CUE-IN
+   ad_date_range = "daterange" // EXT-X-DATERANGE with SCTE35-OUT
+   ad_discontinuity = "discontinuity" // a period from another origin
+)
+
+// ad_state follows the cue tags of a playlist as it is read
+type ad_state struct {
+   signal string // empty outside of a break
+   left time.Duration // of a break with a duration, zero if until the end tag
+   signals bool // any cue or date range
+}
+
+// cue_out reads a duration such as 30 or DURATION=30
+func (a *ad_state) cue_out(line string) {
+   a.signal, a.left, a.signals = ad_cue, 0, true
+   _, value, ok := strings.Cut(line, ":")
+   if !ok {
+      return
+   }
+   if strings.Contains(value, "=") {
+      value = attributes(line)["DURATION"]
+   }
+   if sec, err := strconv.ParseFloat(value, 64); err == nil {
+      a.left = time.Duration(sec * float64(time.Second))
+   }
+}
+
+func (a *ad_state) date_range(line string) {
+   attr := attributes(line)
+   if _, ok := attr["SCTE35-OUT"]; ok {
+      a.signal, a.left, a.signals = ad_date_range, 0, true
+      value := attr["DURATION"]
+      if value == "" {
+         value = attr["PLANNED-DURATION"]
+      }
+      if sec, err := strconv.ParseFloat(value, 64); err == nil {
+         a.left = time.Duration(sec * float64(time.Second))
+      }
+   }
+   if _, ok := attr["SCTE35-IN"]; ok && a.signal == ad_date_range {
+      a.signal = ""
+   }
+}
+
+// mark returns the signal of a segment of length dur, and ends a break
+// whose duration has passed
+func (a *ad_state) mark(dur time.Duration) string {
+   signal := a.signal
+   if signal != "" && a.left > 0 {
+      a.left -= dur
+      // EXTINF is often rounded
+      if a.left < 100 * time.Millisecond {
+         a.signal = ""
+      }
+   }
+   return signal
+}
+
+// origin is the host and directory of a segment
+func origin(ref string) string {
+   addr, err := url.Parse(ref)
+   if err != nil {
+      return ref
+   }
+   return addr.Host + path.Dir(addr.Path)
+}
+
+// mark_periods is used when a playlist has no cue tags. Periods between
+// discontinuities whose segments all come from somewhere other than most
+// of the playlist are marked as ads.
+func (p *playlist) mark_periods(periods []int) {
+   if len(periods) == 0 || periods[len(periods) - 1] == 0 {
+      return
+   }
+   lengths := make(map[string]time.Duration)
+   for _, seg := range p.segments {
+      lengths[origin(seg.ref)] += seg.duration
+   }
+   var main string
+   for key, length := range lengths {
+      if main == "" || length > lengths[main] {
+         main = key
+      }
+   }
+   content := make(map[int]bool)
+   for i, seg := range p.segments {
+      if origin(seg.ref) == main {
+         content[periods[i]] = true
+      }
+   }
+   for i := range p.segments {
+      if !content[periods[i]] {
+         p.segments[i].ad = ad_discontinuity
+      }
+   }
+}
+
+// Info_Break is an ad break that Strip_Ads drops, by its place in the
+// playlist before anything was dropped
+type Info_Break struct {
+   Start float64 `json:"start"` // seconds
+   End float64 `json:"end"`
+   Segments int `json:"segments"`
+   Signal string `json:"signal"`
+}
+
+func (i Info_Break) String() string {
+   var b []byte
+   b = append(b, "Stripped "...)
+   b = strconv.AppendFloat(b, i.Start, 'f', -1, 64)
+   b = append(b, "s-"...)
+   b = strconv.AppendFloat(b, i.End, 'f', -1, 64)
+   b = append(b, "s Segments:"...)
+   b = strconv.AppendInt(b, int64(i.Segments), 10)
+   b = append(b, " Signal:"...)
+   b = append(b, i.Signal...)
+   return string(b)
+}
+
+// strip drops the segments marked as ads, and returns each break
+func (p *playlist) strip() []Info_Break {
+   var (
+      breaks []Info_Break
+      kept []segment
+      pos time.Duration
+   )
+   for i, seg := range p.segments {
+      if seg.ad != "" {
+         if i == 0 || p.segments[i-1].ad != seg.ad {
+            breaks = append(breaks, Info_Break{
+               Start: pos.Seconds(), End: pos.Seconds(), Signal: seg.ad,
+            })
+         }
+         brk := &breaks[len(breaks) - 1]
+         brk.End = (pos + seg.duration).Seconds()
+         brk.Segments++
+         p.duration -= seg.duration
+      } else {
+         kept = append(kept, seg)
+      }
+      pos += seg.duration
+   }
+   p.segments = kept
+   return breaks
+}
+
+// retimer moves the timestamps of the segments after a break, so that the
+// content that is kept plays without a gap
+type retimer struct {
+   // fragmented MP4
+   scales map[uint32]uint32
+   trexs map[uint32]*mp4.TrexBox
+   next map[uint32]int64
+   offsets map[uint32]int64
+   // TS, in 90 kHz and modulo 33 bits
+   ts_next int64
+   ts_offset int64
+   ts_started bool
+}
+
+// new_retimer takes the EXT-X-MAP of the playlist, nil for TS
+func new_retimer(init []byte) (*retimer, error) {
+   re := retimer{
+      next: make(map[uint32]int64),
+      offsets: make(map[uint32]int64),
+      scales: make(map[uint32]uint32),
+      trexs: make(map[uint32]*mp4.TrexBox),
+   }
+   if init == nil {
+      return &re, nil
+   }
+   file, err := mp4.DecodeFile(bytes.NewReader(init))
+   if err != nil {
+      return nil, err
+   }
+   if file.Moov == nil {
+      return nil, errors.New("moov not found")
+   }
+   for _, trak := range file.Moov.Traks {
+      re.scales[trak.Tkhd.TrackID] = trak.Mdia.Mdhd.Timescale
+   }
+   if file.Moov.Mvex != nil {
+      for _, trex := range file.Moov.Mvex.Trexs {
+         re.trexs[trex.TrackID] = trex
+      }
+   }
+   return &re, nil
+}
+
+func (r *retimer) segment(body []byte, extinf time.Duration) ([]byte, error) {
+   if len(body) >= ts_packet_size && body[0] == 'G' {
+      return r.ts(body, extinf)
+   }
+   return r.fragment(body)
+}
+
+// fragment moves each track by whole gaps of a second or more, so rounding
+// in the source is kept
+func (r *retimer) fragment(body []byte) ([]byte, error) {
+   file, err := mp4.DecodeFile(bytes.NewReader(body))
+   if err != nil {
+      return nil, err
+   }
+   for _, seg := range file.Segments {
+      for _, frag := range seg.Fragments {
+         for _, traf := range frag.Moof.Trafs {
+            if traf.Tfdt == nil {
+               continue
+            }
+            id := traf.Tfhd.TrackID
+            scale := int64(r.scales[id])
+            if scale == 0 {
+               scale = 90_000
+            }
+            at := int64(traf.Tfdt.BaseMediaDecodeTime) + r.offsets[id]
+            if next, ok := r.next[id]; ok {
+               if gap := next - at; gap >= scale || -gap >= scale {
+                  r.offsets[id] += gap
+                  at = next
+               }
+            }
+            if at < 0 || traf.Tfdt.Version == 0 && at >= 1 << 32 {
+               return nil, errors.New("tfdt out of range")
+            }
+            traf.Tfdt.BaseMediaDecodeTime = uint64(at)
+            r.next[id] = at + int64(verify.Traf_Duration(traf, r.trexs[id]))
+         }
+      }
+   }
+   file.FragEncMode = mp4.EncModeBoxTree
+   var buf bytes.Buffer
+   if err := file.Encode(&buf); err != nil {
+      return nil, err
+   }
+   return buf.Bytes(), nil
+}
+
+const ts_packet_size = 188
+
+// ts moves every PTS, DTS and PCR by one offset, found from the first PTS
+// of the segment and the EXTINF before it
+func (r *retimer) ts(body []byte, extinf time.Duration) ([]byte, error) {
+   body = append([]byte(nil), body...)
+   var pcrs, stamps [][]byte
+   for pos := 0; pos + ts_packet_size <= len(body); pos += ts_packet_size {
+      packet := body[pos:pos + ts_packet_size]
+      if packet[0] != 'G' {
+         return nil, errors.New("TS sync byte not found")
+      }
+      payload := 4
+      if packet[3] & 0x20 != 0 {
+         length := int(packet[4])
+         if length >= 7 && packet[5] & 0x10 != 0 {
+            pcrs = append(pcrs, packet[6:12])
+         }
+         payload += 1 + length
+      }
+      if packet[1] & 0x40 == 0 || payload + 19 > ts_packet_size {
+         continue
+      }
+      pes := packet[payload:]
+      if pes[0] != 0 || pes[1] != 0 || pes[2] != 1 || pes[7] & 0x80 == 0 {
+         continue
+      }
+      stamps = append(stamps, pes[9:14])
+      if pes[7] & 0x40 != 0 {
+         stamps = append(stamps, pes[14:19])
+      }
+   }
+   if len(stamps) == 0 {
+      return body, nil
+   }
+   at := ts_wrap(ts_read(stamps[0]) + r.ts_offset)
+   if r.ts_started {
+      gap := ts_wrap(r.ts_next - at)
+      if gap >= 1 << 32 {
+         gap -= 1 << 33
+      }
+      if gap >= 90_000 || -gap >= 90_000 {
+         r.ts_offset = ts_wrap(r.ts_offset + gap)
+         at = r.ts_next
+      }
+   }
+   r.ts_started = true
+   r.ts_next = ts_wrap(at + int64(extinf * 90_000 / time.Second))
+   if r.ts_offset == 0 {
+      return body, nil
+   }
+   for _, stamp := range stamps {
+      ts_write(stamp, ts_wrap(ts_read(stamp) + r.ts_offset))
+   }
+   for _, pcr := range pcrs {
+      base := int64(pcr[0]) << 25 | int64(pcr[1]) << 17 |
+         int64(pcr[2]) << 9 | int64(pcr[3]) << 1 | int64(pcr[4] >> 7)
+      base = ts_wrap(base + r.ts_offset)
+      pcr[0] = byte(base >> 25)
+      pcr[1] = byte(base >> 17)
+      pcr[2] = byte(base >> 9)
+      pcr[3] = byte(base >> 1)
+      pcr[4] = pcr[4] & 0x7F | byte(base << 7)
+   }
+   return body, nil
+}
+
+func ts_read(b []byte) int64 {
+   return int64(b[0] >> 1 & 7) << 30 | int64(b[1]) << 22 |
+      int64(b[2] >> 1) << 15 | int64(b[3]) << 7 | int64(b[4] >> 1)
+}
+
+// ts_write keeps the prefix and marker bits
+func ts_write(b []byte, v int64) {
+   b[0] = b[0] & 0xF0 | byte(v >> 29) & 0x0E | 1
+   b[1] = byte(v >> 22)
+   b[2] = byte(v >> 14) | 1
+   b[3] = byte(v >> 7)
+   b[4] = byte(v << 1) | 1
+}
+
+// timestamps are 33 bits
+func ts_wrap(v int64) int64 {
+   return v & (1 << 33 - 1)
+}
@@ -0,0 +1,167 @@
+package mech
+
+import (
+   "bytes"
+   "fmt"
+   "github.com/edgeware/mp4ff/mp4"
+   "strings"
+   "testing"
+   "time"
+)
+
+const ad_playlist = `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXTINF:4,
+0.ts
+#EXT-X-CUE-OUT:8
+#EXTINF:4,
+ad/0.ts
+#EXTINF:4,
+ad/1.ts
+#EXTINF:4,
+1.ts
+#EXT-X-DATERANGE:ID="1",SCTE35-OUT=0xFC,DURATION=4
+#EXTINF:4,
+ad/2.ts
+#EXT-X-CUE-OUT
+#EXTINF:4,
+ad/3.ts
+#EXT-X-CUE-IN
+#EXTINF:4,
+2.ts
+#EXT-X-ENDLIST
+`
+
+const discontinuity_playlist = `#EXTM3U
+#EXTINF:4,
+content/0.ts
+#EXT-X-DISCONTINUITY
+#EXTINF:4,
+https://ads.example/0.ts
+#EXT-X-DISCONTINUITY
+#EXTINF:4,
+content/1.ts
+#EXTINF:4,
+content/2.ts
+#EXT-X-ENDLIST
+`
+
+func Test_Strip(t *testing.T) {
+   play, err := new_playlist(strings.NewReader(ad_playlist))
+   if err != nil {
+      t.Fatal(err)
+   }
+   breaks := play.strip()
+   if fmt.Sprint(breaks) != "[Stripped 4s-12s Segments:2 Signal:cue " +
+   "Stripped 16s-20s Segments:1 Signal:daterange " +
+   "Stripped 20s-24s Segments:1 Signal:cue]" {
+      t.Fatal(breaks)
+   }
+   var refs []string
+   for _, seg := range play.segments {
+      refs = append(refs, seg.ref)
+   }
+   if fmt.Sprint(refs) != "[0.ts 1.ts 2.ts]" {
+      t.Fatal(refs)
+   }
+   if play.duration != 12 * time.Second {
+      t.Fatal(play.duration)
+   }
+   play, err = new_playlist(strings.NewReader(discontinuity_playlist))
+   if err != nil {
+      t.Fatal(err)
+   }
+   breaks = play.strip()
+   if len(breaks) != 1 || breaks[0].Signal != ad_discontinuity {
+      t.Fatal(breaks)
+   }
+   if len(play.segments) != 3 {
+      t.Fatal(play.segments)
+   }
+}
+
+// one packet that starts a PES with pts, and a PCR
+func ts_segment(pts uint64) []byte {
+   packet := make([]byte, ts_packet_size)
+   packet[0] = 'G'
+   packet[1] = 0x41
+   packet[3] = 0x30
+   packet[4] = 7
+   packet[5] = 0x10
+   ts_pcr := packet[6:12]
+   ts_pcr[0] = byte(pts >> 25)
+   ts_pcr[1] = byte(pts >> 17)
+   ts_pcr[2] = byte(pts >> 9)
+   ts_pcr[3] = byte(pts >> 1)
+   ts_pcr[4] = byte(pts << 7)
+   pes := packet[12:]
+   copy(pes, []byte{0, 0, 1, 0xE0, 0, 0, 0x80, 0x80, 5, 0x20})
+   ts_write(pes[9:14], int64(pts))
+   return packet
+}
+
+func Test_Retime_TS(t *testing.T) {
+   re, err := new_retimer(nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   // the third segment comes after a break of 30 seconds
+   for i, pts := range []uint64{90_000, 450_000, 3_510_000} {
+      body, err := re.segment(ts_segment(pts), 4 * time.Second)
+      if err != nil {
+         t.Fatal(err)
+      }
+      got := ts_read(body[21:26])
+      if want := 90_000 + int64(i) * 360_000; got != want {
+         t.Fatal(i, got)
+      }
+   }
+}
+
+func fragment(decode uint64) ([]byte, error) {
+   frag, err := mp4.CreateFragment(1, 1)
+   if err != nil {
+      return nil, err
+   }
+   frag.AddFullSample(mp4.FullSample{
+      Sample: mp4.Sample{Dur: 48000, Size: 1},
+      DecodeTime: decode,
+      Data: []byte{1},
+   })
+   var buf bytes.Buffer
+   if err := frag.Encode(&buf); err != nil {
+      return nil, err
+   }
+   return buf.Bytes(), nil
+}
+
+func Test_Retime_Fragment(t *testing.T) {
+   init := mp4.CreateEmptyInit()
+   init.AddEmptyTrack(48000, "audio", "und")
+   var buf bytes.Buffer
+   if err := init.Encode(&buf); err != nil {
+      t.Fatal(err)
+   }
+   re, err := new_retimer(buf.Bytes())
+   if err != nil {
+      t.Fatal(err)
+   }
+   for i, decode := range []uint64{0, 48000, 480000, 528000} {
+      body, err := fragment(decode)
+      if err != nil {
+         t.Fatal(err)
+      }
+      body, err = re.segment(body, time.Second)
+      if err != nil {
+         t.Fatal(err)
+      }
+      file, err := mp4.DecodeFile(bytes.NewReader(body))
+      if err != nil {
+         t.Fatal(err)
+      }
+      tfdt := file.Segments[0].Fragments[0].Moof.Traf.Tfdt
+      if tfdt.BaseMediaDecodeTime != uint64(i) * 48000 {
+         t.Fatal(i, tfdt.BaseMediaDecodeTime)
+      }
+   }
+}
@@ -2,13 +2,31 @@ package amc
 
 import (
    "bytes"
+   "context"
    "encoding/json"
+   "github.com/89z/mech/extractor"
    "github.com/89z/rosso/http"
    "github.com/89z/rosso/os"
+   "net/url"
+   "path"
    "strconv"
    "strings"
 )
 
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "amc",
+      Hosts: []string{"amcplus.com"},
+      ID: func(ref *url.URL) (string, error) {
+         nID, err := Get_NID(path.Base(ref.Path))
+         if err != nil {
+            return "", err
+         }
+         return strconv.FormatInt(nID, 10), nil
+      },
+   })
+}
+
 type Auth struct {
    Data struct {
       Access_Token string
@@ -40,6 +58,13 @@ func Open_Auth(name string) (*Auth, error) {
 
 var Client = http.Default_Client
 
+// Base_URL is the AMC+ gateway, which serves both sign in and playback
+type Base_URL struct {
+   Gateway string
+}
+
+var Base = Base_URL{Gateway: "https://gw.cds.amcn.com"}
+
 func Get_NID(input string) (int64, error) {
    _, nID, found := strings.Cut(input, "--")
    if found {
@@ -49,8 +74,12 @@ func Get_NID(input string) (int64, error) {
 }
 
 func Unauth() (*Auth, error) {
+   return Unauth_Context(context.Background())
+}
+
+func Unauth_Context(ctx context.Context) (*Auth, error) {
    req, err := http.NewRequest(
-      "POST", "https://gw.cds.amcn.com/auth-orchestration-id/api/v1/unauth", nil,
+      "POST", Base.Gateway + "/auth-orchestration-id/api/v1/unauth", nil,
    )
    if err != nil {
       return nil, err
@@ -62,7 +91,7 @@ func Unauth() (*Auth, error) {
       "X-Amcn-Platform": {"web"},
       "X-Amcn-Tenant": {"amcn"},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -75,6 +104,12 @@ func Unauth() (*Auth, error) {
 }
 
 func (a *Auth) Login(email, password string) error {
+   return a.Login_Context(context.Background(), email, password)
+}
+
+func (a *Auth) Login_Context(
+   ctx context.Context, email, password string,
+) error {
    buf, err := json.Marshal(map[string]string{
       "email": email,
       "password": password,
@@ -83,7 +118,7 @@ func (a *Auth) Login(email, password string) error {
       return err
    }
    req, err := http.NewRequest(
-      "POST", "https://gw.cds.amcn.com/auth-orchestration-id/api/v1/login",
+      "POST", Base.Gateway + "/auth-orchestration-id/api/v1/login",
       bytes.NewReader(buf),
    )
    if err != nil {
@@ -102,7 +137,7 @@ func (a *Auth) Login(email, password string) error {
       "X-Amcn-Tenant": {"amcn"},
       "X-Ccpa-Do-Not-Sell": {"doNotPassData"},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return err
    }
@@ -111,16 +146,20 @@ func (a *Auth) Login(email, password string) error {
 }
 
 func (a *Auth) Refresh() error {
+   return a.Refresh_Context(context.Background())
+}
+
+func (a *Auth) Refresh_Context(ctx context.Context) error {
    req, err := http.NewRequest(
       "POST",
-      "https://gw.cds.amcn.com/auth-orchestration-id/api/v1/refresh",
+      Base.Gateway + "/auth-orchestration-id/api/v1/refresh",
       nil,
    )
    if err != nil {
       return err
    }
    req.Header.Set("Authorization", "Bearer " + a.Data.Refresh_Token)
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return err
    }
@@ -2,7 +2,9 @@ package amc
 
 import (
    "bytes"
+   "context"
    "encoding/json"
+   "github.com/89z/mech/meta"
    "net/http"
    "strconv"
    "strings"
@@ -75,9 +77,26 @@ func (d Data) Get_Name() string {
    return buf.String()
 }
 
+func (d Data) Meta() meta.Data {
+   return meta.Data{
+      Episode: d.Custom_Fields.Episode,
+      Season: d.Custom_Fields.Season,
+      Series: d.Custom_Fields.Show,
+      Site: "amc",
+      Title: d.Name,
+   }
+}
+
 func (a Auth) Playback(nID int64) (*Playback, error) {
+   return a.Playback_Context(context.Background(), nID)
+}
+
+func (a Auth) Playback_Context(
+   ctx context.Context, nID int64,
+) (*Playback, error) {
    var b []byte
-   b = append(b, "https://gw.cds.amcn.com/playback-id/api/v1/playback/"...)
+   b = append(b, Base.Gateway...)
+   b = append(b, "/playback-id/api/v1/playback/"...)
    b = strconv.AppendInt(b, nID, 10)
    var p playback_request
    p.Ad_Tags.Mode = "on-demand"
@@ -101,7 +120,7 @@ func (a Auth) Playback(nID int64) (*Playback, error) {
       "X-Amcn-Tenant": {"amcn"},
       "X-Ccpa-Do-Not-Sell": {"doNotPassData"},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -0,0 +1,37 @@
+package amc
+
+import (
+   "encoding/json"
+   "os"
+   "strings"
+   "testing"
+)
+
+func Test_Playback_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/1052529.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var play Playback
+   if err := json.Unmarshal(buf, &play.body); err != nil {
+      t.Fatal(err)
+   }
+   data := play.Data()
+   if name := data.Get_Name(); name != "Killing Eve-4-1-Episode 1" {
+      t.Fatal(name)
+   }
+   if data.Meta().Series != "Killing Eve" {
+      t.Fatal(data.Meta())
+   }
+   if len(data.Sources) != 4 {
+      t.Fatal(data.Sources)
+   }
+   src := data.Source()
+   if src == nil || !strings.HasSuffix(strings.Split(src.Src, "?")[0], ".mpd") {
+      t.Fatal(src)
+   }
+   // clear content has no key systems
+   if src.Key_Systems != nil {
+      t.Fatal(src.Key_Systems)
+   }
+}
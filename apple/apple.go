@@ -2,6 +2,7 @@ package apple
 
 import (
    "bytes"
+   "context"
    "encoding/json"
    "github.com/89z/rosso/http"
    "github.com/89z/rosso/os"
@@ -41,15 +42,19 @@ type Server_Parameters struct {
 type Auth []*http.Cookie
 
 func (s Signin) Auth() (Auth, error) {
+   return s.Auth_Context(context.Background())
+}
+
+func (s Signin) Auth_Context(ctx context.Context) (Auth, error) {
    req, err := http.NewRequest(
-      "POST", "https://buy.tv.apple.com/account/web/auth", nil,
+      "POST", Base.Buy + "/account/web/auth", nil,
    )
    if err != nil {
       return nil, err
    }
    req.AddCookie(s.my_ac_info())
    req.Header.Set("Origin", "https://tv.apple.com")
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -75,6 +80,21 @@ const (
 
 var Client = http.Default_Client
 
+// Base_URL has the Apple TV hosts, and the Apple ID host that signs in
+type Base_URL struct {
+   Account string
+   Buy string
+   Sign_In string
+   Site string
+}
+
+var Base = Base_URL{
+   Account: "https://amp-account.tv.apple.com",
+   Buy: "https://buy.tv.apple.com",
+   Sign_In: "https://idmsa.apple.com",
+   Site: "https://tv.apple.com",
+}
+
 type Episode struct {
    Data struct {
       Playables map[string]struct {
@@ -84,8 +104,14 @@ type Episode struct {
 }
 
 func New_Episode(content_ID string) (*Episode, error) {
+   return New_Episode_Context(context.Background(), content_ID)
+}
+
+func New_Episode_Context(
+   ctx context.Context, content_ID string,
+) (*Episode, error) {
    req, err := http.NewRequest(
-      "GET", "https://tv.apple.com/api/uts/v3/episodes/" + content_ID, nil,
+      "GET", Base.Site + "/api/uts/v3/episodes/" + content_ID, nil,
    )
    if err != nil {
       return nil, err
@@ -97,7 +123,7 @@ func New_Episode(content_ID string) (*Episode, error) {
       "sf": {strconv.Itoa(sf_max)},
       "v": {strconv.Itoa(v_max)},
    }.Encode()
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -123,7 +149,17 @@ type Config struct {
 }
 
 func New_Config() (*Config, error) {
-   res, err := Client.Get("https://amp-account.tv.apple.com/account/web/config")
+   return New_Config_Context(context.Background())
+}
+
+func New_Config_Context(ctx context.Context) (*Config, error) {
+   req, err := http.NewRequest(
+      "GET", Base.Account + "/account/web/config", nil,
+   )
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -148,7 +184,15 @@ type Environment struct {
 }
 
 func New_Environment() (*Environment, error) {
-   res, err := Client.Get("https://tv.apple.com")
+   return New_Environment_Context(context.Background())
+}
+
+func New_Environment_Context(ctx context.Context) (*Environment, error) {
+   req, err := http.NewRequest("GET", Base.Site, nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -179,6 +223,12 @@ func New_Environment() (*Environment, error) {
 }
 
 func (c Config) Signin(email, password string) (Signin, error) {
+   return c.Signin_Context(context.Background(), email, password)
+}
+
+func (c Config) Signin_Context(
+   ctx context.Context, email, password string,
+) (Signin, error) {
    buf, err := json.Marshal(map[string]string{
      "accountName": email,
      "password": password,
@@ -187,7 +237,7 @@ func (c Config) Signin(email, password string) (Signin, error) {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", "https://idmsa.apple.com/appleauth/auth/signin",
+      "POST", Base.Sign_In + "/appleauth/auth/signin",
       bytes.NewReader(buf),
    )
    if err != nil {
@@ -197,7 +247,7 @@ func (c Config) Signin(email, password string) (Signin, error) {
       "Content-Type": {"application/json"},
       "X-Apple-Widget-Key": {c.WebBag.AppIdKey},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -1,12 +1,47 @@
 package apple
 
 import (
+   "encoding/json"
    "fmt"
+   "github.com/89z/mech/cassette"
+   "net/http"
+   "net/http/httptest"
    "os"
+   "strings"
    "testing"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/apple.json", &Client))
+}
+
+func Test_Episode_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/episodes.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var episode Episode
+   if err := json.Unmarshal(buf, &episode); err != nil {
+      t.Fatal(err)
+   }
+   asset := episode.Asset()
+   if asset == nil {
+      t.Fatal(episode)
+   }
+   if !strings.Contains(asset.HlsUrl, "/playlist.m3u8?") {
+      t.Fatal(asset.HlsUrl)
+   }
+   if !strings.HasSuffix(asset.FpsKeyServerUrl, "/subscription/license") {
+      t.Fatal(asset.FpsKeyServerUrl)
+   }
+   params := asset.FpsKeyServerQueryParameters
+   if params.Adam_ID != "1524726231" || params.Svc_ID != "tvs.vds.4105" {
+      t.Fatal(params)
+   }
+}
+
 func Test_Asset(t *testing.T) {
+   cassette.Require(t)
    episode, err := New_Episode(content_ID)
    if err != nil {
       t.Fatal(err)
@@ -16,6 +51,11 @@ func Test_Asset(t *testing.T) {
 }
 
 func Test_Create(t *testing.T) {
+   cassette.Require(t)
+   email, password := os.Getenv("MECH_EMAIL"), os.Getenv("MECH_PASSWORD")
+   if email == "" {
+      t.Skip("MECH_EMAIL is not set")
+   }
    con, err := New_Config()
    if err != nil {
       t.Fatal(err)
@@ -36,3 +76,26 @@ func Test_Create(t *testing.T) {
       t.Fatal(err)
    }
 }
+
+func Test_Base(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.URL.Path != "/api/uts/v3/episodes/" + content_ID {
+            http.NotFound(w, r)
+            return
+         }
+         http.ServeFile(w, r, "testdata/episodes.json")
+      },
+   ))
+   defer server.Close()
+   base := Base
+   defer func() { Base = base }()
+   Base.Site = server.URL
+   episode, err := New_Episode(content_ID)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if episode.Asset() == nil {
+      t.Fatal(episode)
+   }
+}
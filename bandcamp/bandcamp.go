@@ -1,7 +1,9 @@
 package bandcamp
 
 import (
+   "context"
    "encoding/json"
+   "github.com/89z/mech/meta"
    "net/http"
    "net/url"
    "strconv"
@@ -18,15 +20,13 @@ type Band struct {
    Discography []Item
 }
 
-func new_band(id int) (*Band, error) {
-   req, err := http.NewRequest(
-      "GET", "http://bandcamp.com/api/mobile/24/band_details", nil,
-   )
+func new_band(ctx context.Context, id int) (*Band, error) {
+   req, err := http.NewRequest("GET", Base.API + "/band_details", nil)
    if err != nil {
       return nil, err
    }
    req.URL.RawQuery = "band_id=" + strconv.Itoa(id)
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -108,15 +108,23 @@ type Item struct {
 }
 
 func (i Item) Band() (*Band, error) {
-   return new_band(i.Band_ID)
+   return i.Band_Context(context.Background())
+}
+
+func (i Item) Band_Context(ctx context.Context) (*Band, error) {
+   return new_band(ctx, i.Band_ID)
 }
 
 func (i Item) Tralbum() (*Tralbum, error) {
+   return i.Tralbum_Context(context.Background())
+}
+
+func (i Item) Tralbum_Context(ctx context.Context) (*Tralbum, error) {
    switch i.Item_Type {
    case "album":
-      return new_tralbum('a', i.Item_ID)
+      return new_tralbum(ctx, 'a', i.Item_ID)
    case "track":
-      return new_tralbum('t', i.Item_ID)
+      return new_tralbum(ctx, 't', i.Item_ID)
    }
    return nil, invalid_type{i.Item_Type}
 }
@@ -134,6 +142,10 @@ func (t Track) Name() string {
    return t.Band_Name + "-" + t.Title
 }
 
+func (t Track) Meta() meta.Data {
+   return meta.Data{Author: t.Band_Name, Site: "bandcamp", Title: t.Title}
+}
+
 type Tralbum struct {
    Art_ID int64
    Release_Date int64
@@ -142,10 +154,10 @@ type Tralbum struct {
    Tracks []Track
 }
 
-func new_tralbum(typ byte, id int) (*Tralbum, error) {
-   req, err := http.NewRequest(
-      "GET", "http://bandcamp.com/api/mobile/24/tralbum_details", nil,
-   )
+func new_tralbum(
+   ctx context.Context, typ byte, id int,
+) (*Tralbum, error) {
+   req, err := http.NewRequest("GET", Base.API + "/tralbum_details", nil)
    if err != nil {
       return nil, err
    }
@@ -154,7 +166,7 @@ func new_tralbum(typ byte, id int) (*Tralbum, error) {
       "tralbum_id": {strconv.Itoa(id)},
       "tralbum_type": {string(typ)},
    }.Encode()
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
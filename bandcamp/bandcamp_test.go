@@ -2,6 +2,8 @@ package bandcamp
 
 import (
    "fmt"
+   "net/http"
+   "net/http/httptest"
    "testing"
 )
 
@@ -13,3 +15,33 @@ func Test_Image(t *testing.T) {
       fmt.Println(ref)
    }
 }
+
+func Test_Band_Fixture(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.URL.Path != "/band_details" {
+            http.NotFound(w, r)
+            return
+         }
+         http.ServeFile(w, r, "testdata/" + r.URL.RawQuery + ".json")
+      },
+   ))
+   defer server.Close()
+   base := Base
+   defer func() { Base = base }()
+   Base.API = server.URL
+   band, err := Item{Band_ID: 3454424886}.Band()
+   if err != nil {
+      t.Fatal(err)
+   }
+   if band.Name != "Ulrich Schnauss & Jonas Munk" {
+      t.Fatal(band.Name)
+   }
+   if len(band.Discography) != 3 {
+      t.Fatal(band.Discography)
+   }
+   item := band.Discography[0]
+   if item != (Item{Band_ID: 3454424886, Item_ID: 3596433032, Item_Type: "album"}) {
+      t.Fatal(item)
+   }
+}
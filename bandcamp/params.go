@@ -1,6 +1,7 @@
 package bandcamp
 
 import (
+   "context"
    "encoding/json"
    "github.com/89z/rosso/http"
    "github.com/89z/rosso/xml"
@@ -9,6 +10,13 @@ import (
 
 var Client = http.Default_Client
 
+// Base_URL is the address of the mobile API
+type Base_URL struct {
+   API string // mobile API
+}
+
+var Base = Base_URL{API: "http://bandcamp.com/api/mobile/24"}
+
 type Params struct {
    A_ID int
    I_ID int
@@ -16,7 +24,15 @@ type Params struct {
 }
 
 func New_Params(ref string) (*Params, error) {
-   res, err := Client.Get(ref)
+   return New_Params_Context(context.Background(), ref)
+}
+
+func New_Params_Context(ctx context.Context, ref string) (*Params, error) {
+   req, err := http.NewRequest("GET", ref, nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -42,15 +58,23 @@ func New_Params(ref string) (*Params, error) {
 }
 
 func (p Params) Band() (*Band, error) {
-   return new_band(p.A_ID)
+   return p.Band_Context(context.Background())
+}
+
+func (p Params) Band_Context(ctx context.Context) (*Band, error) {
+   return new_band(ctx, p.A_ID)
 }
 
 func (p Params) Tralbum() (*Tralbum, error) {
+   return p.Tralbum_Context(context.Background())
+}
+
+func (p Params) Tralbum_Context(ctx context.Context) (*Tralbum, error) {
    switch p.I_Type {
    case "a":
-      return new_tralbum('a', p.I_ID)
+      return new_tralbum(ctx, 'a', p.I_ID)
    case "t":
-      return new_tralbum('t', p.I_ID)
+      return new_tralbum(ctx, 't', p.I_ID)
    }
    return nil, invalid_type{p.I_Type}
 }
@@ -2,10 +2,16 @@ package bandcamp
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
+   "os"
    "testing"
    "time"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/bandcamp.json", &Client))
+}
+
 var tests = []string{
    "https://schnaussandmunk.bandcamp.com",
    "https://schnaussandmunk.bandcamp.com/album/passage-2",
@@ -14,6 +20,7 @@ var tests = []string{
 }
 
 func Test_Param(t *testing.T) {
+   cassette.Require(t)
    for _, test := range tests {
       param, err := New_Params(test)
       if err != nil {
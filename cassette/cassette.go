@@ -0,0 +1,424 @@
+// Package cassette records HTTP requests and responses to a file, and plays
+// them back, so that tests can run without the network. In a test:
+//
+//   tape, err := cassette.Open("testdata/roku.json")
+//   if err != nil {
+//      t.Fatal(err)
+//   }
+//   defer tape.Close()
+//   Client = Client.Transport(tape.Transport())
+//
+// Set MECH_RECORD to make the requests and write the file again. Main does
+// this for a whole package.
+package cassette
+
+import (
+   "bytes"
+   "encoding/json"
+   "errors"
+   "io"
+   "net"
+   "net/http"
+   "net/url"
+   "os"
+   "path/filepath"
+   "strconv"
+   "strings"
+   "sync"
+   "unicode/utf8"
+)
+
+// Record_Env is the environment variable that makes Open record
+const Record_Env = "MECH_RECORD"
+
+// Redacted replaces each secret that is written
+const Redacted = "REDACTED"
+
+// headers, and the names of query, form and JSON fields, that are secret. The
+// compare ignores case.
+var (
+   Redact_Headers = []string{
+      "Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie",
+      "X-Api-Key",
+   }
+   Redact_Fields = []string{
+      "access_token", "api_key", "client_id", "client_secret", "device_code",
+      "email", "key", "password", "refresh_token", "sig", "signature",
+      "token",
+   }
+)
+
+type Request struct {
+   Method string `json:"method"`
+   URL string `json:"url"`
+   Header http.Header `json:"header,omitempty"`
+   Body string `json:"body,omitempty"`
+   Body_Base64 []byte `json:"body_base64,omitempty"` // if not UTF-8
+}
+
+type Response struct {
+   Status int `json:"status"`
+   Header http.Header `json:"header,omitempty"`
+   Body string `json:"body,omitempty"`
+   Body_Base64 []byte `json:"body_base64,omitempty"`
+}
+
+type Interaction struct {
+   Request Request `json:"request"`
+   Response Response `json:"response"`
+}
+
+// Cassette is an http.RoundTripper. When replaying, each request takes the
+// first unused interaction with the same method, URL and body, after
+// redaction.
+type Cassette struct {
+   Interactions []Interaction `json:"interactions"`
+   Round_Tripper http.RoundTripper `json:"-"` // when recording, nil for http.DefaultTransport
+   mu sync.Mutex
+   name string
+   record bool
+   used []bool
+}
+
+// Open is Record if MECH_RECORD is set, or else Replay
+func Open(name string) (*Cassette, error) {
+   if os.Getenv(Record_Env) != "" {
+      return Record(name), nil
+   }
+   return Replay(name)
+}
+
+// Record makes each request, and Close writes them to name
+func Record(name string) *Cassette {
+   return &Cassette{name: name, record: true}
+}
+
+// Replay reads name, and makes no requests
+func Replay(name string) (*Cassette, error) {
+   buf, err := os.ReadFile(name)
+   if err != nil {
+      return nil, err
+   }
+   tape := &Cassette{name: name}
+   if err := json.Unmarshal(buf, tape); err != nil {
+      return nil, err
+   }
+   tape.used = make([]bool, len(tape.Interactions))
+   return tape, nil
+}
+
+// Main runs the tests of a package with the cassette name, which is put in
+// the client of the package:
+//
+//   func TestMain(m *testing.M) {
+//      os.Exit(cassette.Main(m.Run, "testdata/roku.json", &Client))
+//   }
+//
+// If the cassette does not exist, the tests still run, and those that call
+// Require are skipped. Requests to this machine, such as to an httptest
+// server, go around the cassette. A recording is written only if the tests
+// pass. The result is for os.Exit.
+func Main[T client[T]](run func() int, name string, c *T) int {
+   tape, err := Open(name)
+   if os.IsNotExist(err) {
+      os.Stderr.WriteString(name + " does not exist, so tests that use the ")
+      os.Stderr.WriteString("network are skipped. Set " + Record_Env)
+      os.Stderr.WriteString(" to record it.\n")
+      missing = name
+      tape, err = &Cassette{name: name}, nil
+   }
+   if err != nil {
+      os.Stderr.WriteString(err.Error() + "\n")
+      return 1
+   }
+   tr := new(http.Transport)
+   tr.RegisterProtocol("http", local{tape})
+   tr.RegisterProtocol("https", local{tape})
+   *c = (*c).Transport(tr)
+   code := run()
+   if code != 0 {
+      // a failed recording would replace the cassette with a partial one
+      return code
+   }
+   if err := tape.Close(); err != nil {
+      os.Stderr.WriteString(err.Error() + "\n")
+      return 1
+   }
+   return code
+}
+
+// missing is the cassette that Main did not find
+var missing string
+
+// Require skips t, a *testing.T, if Main did not find the cassette. Tests
+// that use the network call it first.
+func Require(t interface{ Skip(...any) }) {
+   if missing != "" {
+      t.Skip("no cassette " + missing)
+   }
+}
+
+// client is the Client of a site package
+type client[T any] interface {
+   Transport(*http.Transport) T
+}
+
+// Transport is for the Transport method of rosso http.Client
+func (c *Cassette) Transport() *http.Transport {
+   tr := new(http.Transport)
+   tr.RegisterProtocol("http", c)
+   tr.RegisterProtocol("https", c)
+   return tr
+}
+
+// Close writes the cassette when recording
+func (c *Cassette) Close() error {
+   if !c.record {
+      return nil
+   }
+   c.mu.Lock()
+   defer c.mu.Unlock()
+   buf, err := json.MarshalIndent(c, "", " ")
+   if err != nil {
+      return err
+   }
+   if err := os.MkdirAll(filepath.Dir(c.name), os.ModePerm); err != nil {
+      return err
+   }
+   return os.WriteFile(c.name, append(buf, '\n'), 0666)
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+   body, err := read_body(&req.Body)
+   if err != nil {
+      return nil, err
+   }
+   want := new_request(req, body)
+   if c.record {
+      return c.round_trip(req, want)
+   }
+   c.mu.Lock()
+   defer c.mu.Unlock()
+   for i, act := range c.Interactions {
+      if c.used[i] || !act.Request.match(want) {
+         continue
+      }
+      c.used[i] = true
+      return act.Response.response(req), nil
+   }
+   return nil, errors.New(
+      "cassette: no interaction for " + want.Method + " " + want.URL,
+   )
+}
+
+func (c *Cassette) round_trip(
+   req *http.Request, want Request,
+) (*http.Response, error) {
+   rt := c.Round_Tripper
+   if rt == nil {
+      rt = http.DefaultTransport
+   }
+   res, err := rt.RoundTrip(req)
+   if err != nil {
+      return nil, err
+   }
+   body, err := read_body(&res.Body)
+   if err != nil {
+      return nil, err
+   }
+   got := Response{Status: res.StatusCode, Header: redact_header(res.Header)}
+   got.Body, got.Body_Base64 = encode_body(Redact_Body(body))
+   c.mu.Lock()
+   c.Interactions = append(c.Interactions, Interaction{want, got})
+   c.mu.Unlock()
+   return res, nil
+}
+
+// local sends requests for this machine past the cassette
+type local struct {
+   *Cassette
+}
+
+func (l local) RoundTrip(req *http.Request) (*http.Response, error) {
+   host := req.URL.Hostname()
+   if ip := net.ParseIP(host); host == "localhost" || ip.IsLoopback() {
+      return http.DefaultTransport.RoundTrip(req)
+   }
+   return l.Cassette.RoundTrip(req)
+}
+
+// read_body reads the whole body, and leaves one that can be read again
+func read_body(body *io.ReadCloser) ([]byte, error) {
+   if *body == nil || *body == http.NoBody {
+      return nil, nil
+   }
+   buf, err := io.ReadAll(*body)
+   (*body).Close()
+   if err != nil {
+      return nil, err
+   }
+   *body = io.NopCloser(bytes.NewReader(buf))
+   return buf, nil
+}
+
+func new_request(req *http.Request, body []byte) Request {
+   ref := *req.URL
+   ref.RawQuery = redact_values(ref.RawQuery)
+   out := Request{
+      Header: redact_header(req.Header),
+      Method: req.Method,
+      URL: ref.String(),
+   }
+   if strings.HasPrefix(
+      req.Header.Get("Content-Type"), "application/x-www-form-urlencoded",
+   ) {
+      body = []byte(redact_values(string(body)))
+   } else {
+      body = Redact_Body(body)
+   }
+   out.Body, out.Body_Base64 = encode_body(body)
+   return out
+}
+
+func (r Request) match(s Request) bool {
+   if r.Method != s.Method || r.URL != s.URL || r.Body != s.Body {
+      return false
+   }
+   return bytes.Equal(r.Body_Base64, s.Body_Base64)
+}
+
+func (r Response) response(req *http.Request) *http.Response {
+   body := r.Body_Base64
+   if body == nil {
+      body = []byte(r.Body)
+   }
+   head := r.Header.Clone()
+   if head == nil {
+      head = make(http.Header)
+   }
+   head.Del("Content-Length")
+   return &http.Response{
+      Body: io.NopCloser(bytes.NewReader(body)),
+      ContentLength: int64(len(body)),
+      Header: head,
+      Proto: "HTTP/1.1",
+      ProtoMajor: 1,
+      ProtoMinor: 1,
+      Request: req,
+      Status: strconv.Itoa(r.Status) + " " + http.StatusText(r.Status),
+      StatusCode: r.Status,
+   }
+}
+
+func encode_body(body []byte) (string, []byte) {
+   if utf8.Valid(body) {
+      return string(body), nil
+   }
+   return "", body
+}
+
+func secret(name string, names []string) bool {
+   for _, s := range names {
+      if strings.EqualFold(s, name) {
+         return true
+      }
+   }
+   return false
+}
+
+// redact_header also drops Content-Length, as redacted bodies change length
+func redact_header(head http.Header) http.Header {
+   if len(head) == 0 {
+      return nil
+   }
+   out := make(http.Header)
+   for key, values := range head {
+      switch {
+      case key == "Content-Length":
+      case secret(key, Redact_Headers):
+         out[key] = []string{Redacted}
+      default:
+         out[key] = values
+      }
+   }
+   return out
+}
+
+// redact_values works on a query or form. Names that are not secret are
+// kept as they were, so that order is kept.
+func redact_values(raw string) string {
+   if raw == "" {
+      return raw
+   }
+   pairs := strings.Split(raw, "&")
+   for i, pair := range pairs {
+      key, _, _ := strings.Cut(pair, "=")
+      name, err := url.QueryUnescape(key)
+      if err == nil && secret(name, Redact_Fields) {
+         pairs[i] = key + "=" + Redacted
+      }
+   }
+   return strings.Join(pairs, "&")
+}
+
+// Redact_Body changes JSON with secret fields, or addresses with secret
+// query values, and leaves anything else
+func Redact_Body(body []byte) []byte {
+   dec := json.NewDecoder(bytes.NewReader(body))
+   dec.UseNumber()
+   var value any
+   if dec.Decode(&value) != nil || dec.More() {
+      return body
+   }
+   value, changed := redact_JSON(value)
+   if !changed {
+      return body
+   }
+   var buf bytes.Buffer
+   enc := json.NewEncoder(&buf)
+   enc.SetEscapeHTML(false)
+   if err := enc.Encode(value); err != nil {
+      return body
+   }
+   return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+}
+
+// redact_JSON reports whether anything was redacted
+func redact_JSON(value any) (any, bool) {
+   var changed bool
+   switch value := value.(type) {
+   case map[string]any:
+      for key, field := range value {
+         if _, ok := field.(string); ok && secret(key, Redact_Fields) {
+            value[key] = Redacted
+            changed = true
+         } else if field, ok := redact_JSON(field); ok {
+            value[key] = field
+            changed = true
+         }
+      }
+   case []any:
+      for i, field := range value {
+         if field, ok := redact_JSON(field); ok {
+            value[i] = field
+            changed = true
+         }
+      }
+   case string:
+      out := redact_address(value)
+      return out, out != value
+   }
+   return value, changed
+}
+
+// redact_address works on the query of a string that is an address
+func redact_address(s string) string {
+   if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+      return s
+   }
+   before, query, ok := strings.Cut(s, "?")
+   if !ok {
+      return s
+   }
+   return before + "?" + redact_values(query)
+}
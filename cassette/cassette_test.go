@@ -0,0 +1,146 @@
+package cassette
+
+import (
+   "io"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "os"
+   "strings"
+   "testing"
+)
+
+func get(client *http.Client, ref string) (string, error) {
+   req, err := http.NewRequest(
+      "POST", ref, strings.NewReader(`{"password":"hunter2","id":1}`),
+   )
+   if err != nil {
+      return "", err
+   }
+   req.Header.Set("Authorization", "Bearer secret")
+   res, err := client.Do(req)
+   if err != nil {
+      return "", err
+   }
+   defer res.Body.Close()
+   body, err := io.ReadAll(res.Body)
+   if err != nil {
+      return "", err
+   }
+   return string(body), nil
+}
+
+func Test_Cassette(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         io.WriteString(w, `{"access_token":"live","user":"`)
+         io.WriteString(w, r.URL.Query().Get("user") + `"}`)
+      },
+   ))
+   name := t.TempDir() + "/testdata/cassette.json"
+   ref := server.URL + "/login?user=a&token=secret"
+   tape := Record(name)
+   // as the Transport method of rosso http.Client does
+   client := &http.Client{Transport: tape.Transport()}
+   body, err := get(client, ref)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if body != `{"access_token":"live","user":"a"}` {
+      t.Fatal(body)
+   }
+   if err := tape.Close(); err != nil {
+      t.Fatal(err)
+   }
+   server.Close()
+   buf, err := os.ReadFile(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   for _, secret := range []string{"hunter2", "Bearer", "live", "token=secret"} {
+      if strings.Contains(string(buf), secret) {
+         t.Fatal(secret)
+      }
+   }
+   tape, err = Replay(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   client.Transport = tape.Transport()
+   // the token is different, but the request still matches
+   other, err := url.Parse(ref)
+   if err != nil {
+      t.Fatal(err)
+   }
+   other.RawQuery = "user=a&token=other"
+   body, err = get(client, other.String())
+   if err != nil {
+      t.Fatal(err)
+   }
+   if body != `{"access_token":"REDACTED","user":"a"}` {
+      t.Fatal(body)
+   }
+   // each interaction is used once
+   if _, err := get(client, ref); err == nil {
+      t.Fatal("replayed twice")
+   }
+}
+
+func Test_Redact_Body(t *testing.T) {
+   body := Redact_Body([]byte(
+      `{"items":[{"src":"https://example.com/a.mpd?token=1&id=2"}],"n":1.50}`,
+   ))
+   want := `{"items":[{"src":"https://example.com/a.mpd?token=REDACTED&id=2"}],"n":1.50}`
+   if string(body) != want {
+      t.Fatal(string(body))
+   }
+}
+
+type test_client struct {
+   *http.Client
+}
+
+func (test_client) Transport(tr *http.Transport) test_client {
+   return test_client{&http.Client{Transport: tr}}
+}
+
+type skipper struct {
+   skipped bool
+}
+
+func (s *skipper) Skip(...any) {
+   s.skipped = true
+}
+
+// a missing cassette does not fail the package, and the local server is
+// still reached
+func Test_Main(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         io.WriteString(w, "local")
+      },
+   ))
+   defer server.Close()
+   t.Setenv(Record_Env, "")
+   defer func() { missing = "" }()
+   var (
+      client test_client
+      skip skipper
+   )
+   run := func() int {
+      Require(&skip)
+      if body, err := get(client.Client, server.URL); body != "local" {
+         t.Error(body, err)
+      }
+      if _, err := get(client.Client, "http://example.com"); err == nil {
+         t.Error("example.com")
+      }
+      return 0
+   }
+   if code := Main(run, t.TempDir() + "/none.json", &client); code != 0 {
+      t.Fatal(code)
+   }
+   if !skip.skipped {
+      t.Fatal("Require")
+   }
+}
@@ -1,17 +1,44 @@
 package cbc
 
 import (
+   "context"
    "encoding/json"
    "errors"
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/meta"
    "github.com/89z/rosso/http"
+   "net/url"
    "strings"
    "time"
 )
 
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "cbc",
+      Hosts: []string{"gem.cbc.ca"},
+      ID: func(ref *url.URL) (string, error) {
+         return Get_ID(ref.Path), nil
+      },
+   })
+}
+
 const forwarded_for = "99.224.0.0"
 
 var Client = http.Default_Client
 
+// Base_URL has the Gem API, and the LoginRadius hosts that hold accounts
+type Base_URL struct {
+   API string
+   Login string
+   SSO string
+}
+
+var Base = Base_URL{
+   API: "https://services.radio-canada.ca/ott/cbc-api/v2",
+   Login: "https://api.loginradius.com",
+   SSO: "https://cloud-api.loginradius.com",
+}
+
 // gem.cbc.ca/media/downton-abbey/s01e05
 func Get_ID(input string) string {
    _, after, found := strings.Cut(input, "/media/")
@@ -33,10 +60,19 @@ type Asset struct {
 }
 
 func New_Asset(id string) (*Asset, error) {
+   return New_Asset_Context(context.Background(), id)
+}
+
+func New_Asset_Context(ctx context.Context, id string) (*Asset, error) {
    var buf strings.Builder
-   buf.WriteString("https://services.radio-canada.ca/ott/cbc-api/v2/assets/")
+   buf.WriteString(Base.API)
+   buf.WriteString("/assets/")
    buf.WriteString(id)
-   res, err := Client.Get(buf.String())
+   req, err := http.NewRequest("GET", buf.String(), nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -56,6 +92,16 @@ func (a Asset) Get_Time() time.Time {
    return time.UnixMilli(a.AirDate)
 }
 
+func (a Asset) Meta() meta.Data {
+   return meta.Data{
+      Date: a.Get_Time().Format("2006-01-02"),
+      ID: a.AppleContentId,
+      Series: a.Series,
+      Site: "cbc",
+      Title: a.Title,
+   }
+}
+
 func (a Asset) String() string {
    var buf strings.Builder
    write := func(str string) {
@@ -80,6 +126,12 @@ type Media struct {
 }
 
 func (p Profile) Media(asset *Asset) (*Media, error) {
+   return p.Media_Context(context.Background(), asset)
+}
+
+func (p Profile) Media_Context(
+   ctx context.Context, asset *Asset,
+) (*Media, error) {
    req, err := http.NewRequest("GET", asset.PlaySession.URL, nil)
    if err != nil {
       return nil, err
@@ -88,7 +140,7 @@ func (p Profile) Media(asset *Asset) (*Media, error) {
       "X-Claims-Token": {p.ClaimsToken},
       "X-Forwarded-For": {forwarded_for},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -2,20 +2,29 @@ package cbc
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
+   "net/http"
+   "net/http/httptest"
    "os"
+   "strings"
    "testing"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/cbc.json", &Client))
+}
+
 const downton = "downton-abbey/s01e05"
 
 func Test_Media(t *testing.T) {
+   cassette.Require(t)
    home, err := os.UserHomeDir()
    if err != nil {
       t.Fatal(err)
    }
    profile, err := Open_Profile(home + "/mech/cbc.json")
    if err != nil {
-      t.Fatal(err)
+      t.Skip(err)
    }
    asset, err := New_Asset(downton)
    if err != nil {
@@ -28,3 +37,49 @@ func Test_Media(t *testing.T) {
    }
    fmt.Printf("%+v\n", media)
 }
+
+func Test_Media_Fixture(t *testing.T) {
+   server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+   defer server.Close()
+   var (
+      asset Asset
+      profile Profile
+   )
+   asset.PlaySession.URL = server.URL + "/media-pass.json"
+   media, err := profile.Media(&asset)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if media.URL == nil || !strings.Contains(*media.URL, ".m3u8?") {
+      t.Fatal(media)
+   }
+   asset.PlaySession.URL = server.URL + "/media-fail.json"
+   if _, err := profile.Media(&asset); err == nil {
+      t.Fatal("media-fail")
+   } else if !strings.HasPrefix(err.Error(), "Le média demandé") {
+      t.Fatal(err)
+   }
+}
+
+func Test_Base(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.URL.Path != "/assets/" + downton {
+            http.NotFound(w, r)
+            return
+         }
+         w.Write([]byte(`{"series":"Downton Abbey","title":"local"}`))
+      },
+   ))
+   defer server.Close()
+   base := Base
+   defer func() { Base = base }()
+   Base.API = server.URL
+   asset, err := New_Asset(downton)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if asset.Title != "local" {
+      t.Fatal(asset)
+   }
+}
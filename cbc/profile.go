@@ -2,6 +2,7 @@ package cbc
 
 import (
    "bytes"
+   "context"
    "encoding/json"
    "github.com/89z/rosso/os"
    "net/http"
@@ -38,6 +39,12 @@ type Login struct {
 }
 
 func New_Login(email, password string) (*Login, error) {
+   return New_Login_Context(context.Background(), email, password)
+}
+
+func New_Login_Context(
+   ctx context.Context, email, password string,
+) (*Login, error) {
    buf, err := json.Marshal(map[string]string{
       "email": email,
       "password": password,
@@ -46,7 +53,7 @@ func New_Login(email, password string) (*Login, error) {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", "https://api.loginradius.com/identity/v2/auth/login",
+      "POST", Base.Login + "/identity/v2/auth/login",
       bytes.NewReader(buf),
    )
    if err != nil {
@@ -54,7 +61,7 @@ func New_Login(email, password string) (*Login, error) {
    }
    req.Header.Set("Content-Type", "application/json")
    req.URL.RawQuery = "apiKey=" + api_key
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -67,8 +74,12 @@ func New_Login(email, password string) (*Login, error) {
 }
 
 func (l Login) Web_Token() (*Web_Token, error) {
+   return l.Web_Token_Context(context.Background())
+}
+
+func (l Login) Web_Token_Context(ctx context.Context) (*Web_Token, error) {
    req, err := http.NewRequest(
-      "GET", "https://cloud-api.loginradius.com/sso/jwt/api/token", nil,
+      "GET", Base.SSO + "/sso/jwt/api/token", nil,
    )
    if err != nil {
       return nil, err
@@ -78,7 +89,7 @@ func (l Login) Web_Token() (*Web_Token, error) {
       "apikey": {api_key},
       "jwtapp": {"jwt"},
    }.Encode()
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -95,14 +106,18 @@ type Over_The_Top struct {
 }
 
 func (o Over_The_Top) Profile() (*Profile, error) {
+   return o.Profile_Context(context.Background())
+}
+
+func (o Over_The_Top) Profile_Context(ctx context.Context) (*Profile, error) {
    req, err := http.NewRequest(
-      "GET", "https://services.radio-canada.ca/ott/cbc-api/v2/profile", nil,
+      "GET", Base.API + "/profile", nil,
    )
    if err != nil {
       return nil, err
    }
    req.Header.Set("OTT-Access-Token", o.AccessToken)
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -124,6 +139,12 @@ type Web_Token struct {
 }
 
 func (w Web_Token) Over_The_Top() (*Over_The_Top, error) {
+   return w.Over_The_Top_Context(context.Background())
+}
+
+func (w Web_Token) Over_The_Top_Context(
+   ctx context.Context,
+) (*Over_The_Top, error) {
    buf, err := json.Marshal(map[string]string{
       "jwt": w.Signature,
    })
@@ -131,13 +152,13 @@ func (w Web_Token) Over_The_Top() (*Over_The_Top, error) {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", "https://services.radio-canada.ca/ott/cbc-api/v2/token",
+      "POST", Base.API + "/token",
       bytes.NewReader(buf),
    )
    if err != nil {
       return nil, err
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
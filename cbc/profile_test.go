@@ -1,11 +1,17 @@
 package cbc
 
 import (
+   "github.com/89z/mech/cassette"
    "os"
    "testing"
 )
 
 func Test_Profile(t *testing.T) {
+   cassette.Require(t)
+   email, password := os.Getenv("MECH_EMAIL"), os.Getenv("MECH_PASSWORD")
+   if email == "" {
+      t.Skip("MECH_EMAIL is not set")
+   }
    home, err := os.UserHomeDir()
    if err != nil {
       t.Fatal(err)
@@ -0,0 +1,200 @@
+package mech
+
+import (
+   "encoding/json"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/sink"
+   "github.com/89z/mech/verify"
+   "io"
+   "net/url"
+   "os"
+   "strings"
+   "time"
+)
+
+func clean(name string) string {
+   mapping := func(r rune) rune {
+      if strings.ContainsRune(`"*/:<>?\|`, r) {
+         return -1
+      }
+      return r
+   }
+   return strings.Map(mapping, name)
+}
+
+// path is the output for ext, from Output if set, or else Name
+func (s Stream) path(ext string) string {
+   if s.Output.Is_Set() {
+      return s.Output.Execute(s.Meta, ext)
+   }
+   return clean(s.Name + ext)
+}
+
+// query strings are usually tokens that change with every request, so
+// compare on the rest of the address
+func same_address(a, b string) bool {
+   ref_a, err := url.Parse(a)
+   if err != nil {
+      return false
+   }
+   ref_b, err := url.Parse(b)
+   if err != nil {
+      return false
+   }
+   ref_a.RawQuery = ""
+   ref_b.RawQuery = ""
+   return ref_a.String() == ref_b.String()
+}
+
+type checkpoint struct {
+   Manifest string
+   Variant string
+   Segment int // last completed segment, -1 for initialization only
+   Offset int64
+   name string
+}
+
+func open_checkpoint(name string) (*checkpoint, error) {
+   file, err := os.Open(name)
+   if err != nil {
+      return nil, err
+   }
+   defer file.Close()
+   check := new(checkpoint)
+   if err := json.NewDecoder(file).Decode(check); err != nil {
+      return nil, err
+   }
+   check.name = name
+   return check, nil
+}
+
+func (c checkpoint) create() error {
+   if c.name == "" {
+      return nil
+   }
+   file, err := os.Create(c.name)
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   return json.NewEncoder(file).Encode(c)
+}
+
+// done records segment as written to file. Only a file can be resumed, so
+// other outputs have no checkpoint.
+func (c *checkpoint) done(file io.Writer, segment int) error {
+   seek, ok := file.(io.Seeker)
+   if c.name == "" || !ok {
+      return nil
+   }
+   offset, err := seek.Seek(0, io.SeekCurrent)
+   if err != nil {
+      return err
+   }
+   c.Offset = offset
+   c.Segment = segment
+   return c.create()
+}
+
+func (c checkpoint) remove() error {
+   if c.name == "" {
+      return nil
+   }
+   return os.Remove(c.name)
+}
+
+// resumed reports whether the output already holds the initialization
+// segment
+func (c checkpoint) resumed() bool {
+   return c.Offset >= 1
+}
+
+// sink is Sink, or else files
+func (s Stream) sink() sink.Sink {
+   if s.Sink != nil {
+      return s.Sink
+   }
+   return sink.File{}
+}
+
+// create opens the output name for a variant. With Resume set, a file that
+// matches its checkpoint is truncated to the last completed segment, and
+// writing continues from there. Other sinks start over every time.
+func (s Stream) create(name, variant string) (sink.Output, *checkpoint, error) {
+   check := &checkpoint{
+      Manifest: s.base.String(),
+      Segment: -1,
+      Variant: variant,
+   }
+   if _, ok := s.sink().(sink.File); !ok {
+      file, err := s.Sink.Create(name)
+      if err != nil {
+         return nil, nil, err
+      }
+      return file, check, nil
+   }
+   check.name = name + ".checkpoint"
+   if s.Resume {
+      old, err := open_checkpoint(check.name)
+      if err == nil && old.match(check) {
+         // from before downloads were written to a .part
+         if _, err := os.Stat(name + ".part"); os.IsNotExist(err) {
+            err := os.Rename(name, name + ".part")
+            if err != nil && !os.IsNotExist(err) {
+               return nil, nil, err
+            }
+         }
+         file, err := meta.Open_Part(name)
+         if err == nil {
+            info, err := file.Stat()
+            if err == nil && info.Size() >= old.Offset {
+               os.Stderr.WriteString("Resume " + name + "\n")
+               if err := file.Truncate(old.Offset); err != nil {
+                  return nil, nil, err
+               }
+               if _, err := file.Seek(old.Offset, io.SeekStart); err != nil {
+                  return nil, nil, err
+               }
+               return file, old, nil
+            }
+            file.Close()
+         }
+      }
+   }
+   file, err := meta.Create_Part(name)
+   if err != nil {
+      return nil, nil, err
+   }
+   return file, check, nil
+}
+
+// verify compares the duration of file with want, if Verify is set. Only
+// files can be read back, so other outputs are not checked.
+func (s Stream) verify(file sink.Output, want time.Duration) error {
+   part, ok := file.(*meta.Part)
+   if !s.Verify || !ok {
+      return nil
+   }
+   return verify.File(part.Name(), want)
+}
+
+func (c checkpoint) match(d *checkpoint) bool {
+   if !same_address(c.Manifest, d.Manifest) {
+      return false
+   }
+   return same_address(c.Variant, d.Variant)
+}
+
+// skip_writer drops writes while skip is set, so that an initialization
+// segment already on disk can be parsed again without writing it twice.
+type skip_writer struct {
+   io.Writer
+   skip bool
+}
+
+func (s *skip_writer) Write(buf []byte) (int, error) {
+   if s.skip {
+      return len(buf), nil
+   }
+   return s.Writer.Write(buf)
+}
@@ -0,0 +1,112 @@
+package mech
+
+import (
+   "github.com/89z/mech/meta"
+   "github.com/89z/rosso/hls"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "os"
+   "path/filepath"
+   "testing"
+)
+
+const media_playlist = `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXTINF:4,
+0.ts
+#EXTINF:4,
+1.ts
+#EXTINF:4,
+2.ts
+#EXT-X-ENDLIST
+`
+
+func new_server() *httptest.Server {
+   return httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         switch r.URL.Path {
+         case "/index.m3u8":
+            w.Write([]byte(media_playlist))
+         default:
+            w.Write([]byte("segment" + r.URL.Path + "\n"))
+         }
+      },
+   ))
+}
+
+// chdir moves to a temporary directory until the test is done
+func chdir(t *testing.T) string {
+   dir := t.TempDir()
+   old, err := os.Getwd()
+   if err != nil {
+      t.Fatal(err)
+   }
+   if err := os.Chdir(dir); err != nil {
+      t.Fatal(err)
+   }
+   t.Cleanup(func() {
+      os.Chdir(old)
+   })
+   return dir
+}
+
+func Test_Resume(t *testing.T) {
+   server := new_server()
+   defer server.Close()
+   var str Stream
+   var err error
+   str.base, err = url.Parse(server.URL + "/master.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   chdir(t)
+   str.Name = "resume"
+   str.Resume = true
+   items := hls.Streams{{Raw_URI: "index.m3u8"}}
+   // the segments are TS, so that is the extension
+   name := str.Name + ".ts"
+   // segment 0 is done, and segment 1 was cut off
+   done := "segment/0.ts\n"
+   if err := os.WriteFile(name, []byte(done + "segm"), 0666); err != nil {
+      t.Fatal(err)
+   }
+   check := checkpoint{
+      Manifest: str.base.String(),
+      Offset: int64(len(done)),
+      Segment: 0,
+      Variant: items[0].URI(),
+      name: name + ".checkpoint",
+   }
+   if err := check.create(); err != nil {
+      t.Fatal(err)
+   }
+   if err := str.HLS_Streams(items, 0); err != nil {
+      t.Fatal(err)
+   }
+   buf, err := os.ReadFile(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if string(buf) != done + "segment/1.ts\nsegment/2.ts\n" {
+      t.Fatalf("%q", buf)
+   }
+   if _, err := os.Stat(check.name); !os.IsNotExist(err) {
+      t.Fatal(err)
+   }
+}
+
+func Test_Path(t *testing.T) {
+   var str Stream
+   str.Name = "AC/DC"
+   if name := str.path(".mp4"); name != "ACDC.mp4" {
+      t.Fatal(name)
+   }
+   if err := str.Output.Set("{series}/{title}.{ext}"); err != nil {
+      t.Fatal(err)
+   }
+   str.Meta = meta.Data{Series: "Series", Title: "Title"}
+   if name := str.path(".m4v"); name != filepath.FromSlash("Series/Title.m4v") {
+      t.Fatal(name)
+   }
+}
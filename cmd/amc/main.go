@@ -1,62 +1,12 @@
 package main
 
 import (
-   "flag"
-   "github.com/89z/mech"
-   "github.com/89z/mech/amc"
+   "github.com/89z/mech/cmd/internal/amc"
    "os"
-   "path/filepath"
 )
 
-type flags struct {
-   bandwidth int64
-   email string
-   mech.Stream
-   nid int64
-   password string
-   verbose bool
-}
-
 func main() {
-   home, err := os.UserHomeDir()
-   if err != nil {
+   if err := amc.Main(os.Args[1:]); err != nil {
       panic(err)
    }
-   var f flags
-   // b
-   flag.Int64Var(&f.nid, "b", 0, "NID")
-   // c
-   f.Client_ID = filepath.Join(home, "mech/client_id.bin")
-   flag.StringVar(&f.Client_ID, "c", f.Client_ID, "client ID")
-   // e
-   flag.StringVar(&f.email, "e", "", "email")
-   // f
-   flag.Int64Var(&f.bandwidth, "f", 1_999_999, "video bandwidth")
-   // i
-   flag.BoolVar(&f.Info, "i", false, "information")
-   // k
-   f.Private_Key = filepath.Join(home, "mech/private_key.pem")
-   flag.StringVar(&f.Private_Key, "k", f.Private_Key, "private key")
-   // p
-   flag.StringVar(&f.password, "p", "", "password")
-   // v
-   flag.BoolVar(&f.verbose, "v", false, "verbose")
-   flag.Parse()
-   if f.verbose {
-      amc.Client.Log_Level = 2
-   }
-   if f.email != "" {
-      err := f.login()
-      if err != nil {
-         panic(err)
-      }
-   } else if f.nid >= 1 {
-      err := f.download()
-      if err != nil {
-         panic(err)
-      }
-   } else {
-      flag.Usage()
-   }
 }
-
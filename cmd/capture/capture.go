@@ -0,0 +1,49 @@
+package main
+
+import (
+   "bytes"
+   "encoding/json"
+   "github.com/89z/mech/cassette"
+   "github.com/89z/rosso/http"
+   "io"
+   "os"
+   "path/filepath"
+   "strings"
+)
+
+type flags struct {
+   address string
+   data string
+   header http.Header
+   method string
+   output string
+}
+
+// capture writes the response to output, with secrets redacted the same way
+// as a cassette. JSON is indented, so that fixtures diff well.
+func (f flags) capture() error {
+   req, err := http.NewRequest(f.method, f.address, strings.NewReader(f.data))
+   if err != nil {
+      return err
+   }
+   req.Header = f.header
+   res, err := http.Default_Client.Do(req)
+   if err != nil {
+      return err
+   }
+   defer res.Body.Close()
+   body, err := io.ReadAll(res.Body)
+   if err != nil {
+      return err
+   }
+   body = cassette.Redact_Body(body)
+   var buf bytes.Buffer
+   if json.Indent(&buf, body, "", " ") == nil {
+      body = append(buf.Bytes(), '\n')
+   }
+   if err := os.MkdirAll(filepath.Dir(f.output), os.ModePerm); err != nil {
+      return err
+   }
+   os.Stderr.WriteString("Create " + f.output + "\n")
+   return os.WriteFile(f.output, body, 0666)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+   "flag"
+   "github.com/89z/mech/cassette"
+   "github.com/89z/rosso/http"
+   "strings"
+)
+
+func main() {
+   var f flags
+   f.header = make(http.Header)
+   // a
+   flag.StringVar(&f.address, "a", "", "address")
+   // d
+   flag.StringVar(&f.data, "d", "", "request body")
+   // H
+   flag.Func("H", "request header, such as \"Accept: application/json\"",
+      func(s string) error {
+         key, value, _ := strings.Cut(s, ":")
+         f.header.Add(key, strings.TrimSpace(value))
+         return nil
+      },
+   )
+   // o
+   flag.StringVar(&f.output, "o", "", "fixture, such as amc/testdata/1.json")
+   // redact
+   flag.Func("redact", "more secret field names, comma separated",
+      func(s string) error {
+         names := strings.Split(s, ",")
+         cassette.Redact_Fields = append(cassette.Redact_Fields, names...)
+         return nil
+      },
+   )
+   // X
+   flag.StringVar(&f.method, "X", "GET", "request method")
+   flag.Parse()
+   if f.address != "" && f.output != "" {
+      err := f.capture()
+      if err != nil {
+         panic(err)
+      }
+   } else {
+      flag.Usage()
+   }
+}
@@ -1,6 +1,7 @@
-package main
+package amc
 
 import (
+   "github.com/89z/mech"
    "github.com/89z/mech/amc"
    "os"
 )
@@ -25,17 +26,48 @@ func (f flags) download() error {
       return err
    }
    data := play.Data()
+   if f.JSON != nil {
+      f.JSON.Metadata = data
+   }
    f.Name = data.Get_Name()
+   f.Meta = data.Meta()
    reps, err := f.DASH(data.Source().Src)
    if err != nil {
       return err
    }
    f.Poster = play
-   if err := f.DASH_Get(reps.Audio(), 0); err != nil {
+   audio := reps.Audio()
+   index, err := f.DASH_Index(audio, 0)
+   if err != nil {
+      return err
+   }
+   var tracks []mech.Track
+   f.Tracks = &tracks
+   if err := f.DASH_Get(audio, index); err != nil {
       return err
    }
    video := reps.Video()
-   return f.DASH_Get(video, video.Bandwidth(f.bandwidth))
+   video_index, err := f.DASH_Index(video, video.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   if err := f.DASH_Get(video, video_index); err != nil {
+      return err
+   }
+   text := mech.Text(reps)
+   if len(text) >= 1 && (f.Info || f.subtitle != "") {
+      index, err := f.DASH_Index(text, mech.Text_Index(text, f.subtitle, ""))
+      if err != nil {
+         return err
+      }
+      if err := f.DASH_Text(text, index); err != nil {
+         return err
+      }
+   }
+   if f.mux && !f.Info {
+      return f.Mux(tracks, f.remove)
+   }
+   return nil
 }
 
 func (f flags) login() error {
@@ -1,6 +1,7 @@
-package main
+package cbc
 
 import (
+   "github.com/89z/mech"
    "github.com/89z/mech/cbc"
    "github.com/89z/rosso/hls"
    "os"
@@ -11,19 +12,51 @@ func (f flags) download() error {
    if err != nil {
       return err
    }
+   subs := master.Media.Filter(func(m hls.Medium) bool {
+      return m.Type == "SUBTITLES"
+   })
+   if len(subs) >= 1 && (f.Info || f.subtitle != "") {
+      index := subs.Index(func(a, b hls.Medium) bool {
+         return b.Name == f.subtitle
+      })
+      index, err := f.HLS_Media_Index(subs, index)
+      if err != nil {
+         return err
+      }
+      if err := f.HLS_Subtitles(subs, index); err != nil {
+         return err
+      }
+   }
    media := master.Media.Filter(func(m hls.Medium) bool {
       return m.Type == "AUDIO"
    })
    index := media.Index(func(a, b hls.Medium) bool {
       return b.Name == f.name
    })
+   index, err = f.HLS_Media_Index(media, index)
+   if err != nil {
+      return err
+   }
+   var tracks []mech.Track
+   f.Tracks = &tracks
    if err := f.HLS_Media(media, index); err != nil {
       return err
    }
    streams := master.Streams.Filter(func(s hls.Stream) bool {
       return s.Resolution != ""
    })
-   return f.HLS_Streams(streams, streams.Bandwidth(f.bandwidth))
+   index, err = f.HLS_Streams_Index(streams, streams.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   err = f.HLS_Streams(streams, index)
+   if err != nil {
+      return err
+   }
+   if f.mux && !f.Info {
+      return f.Mux(tracks, f.remove)
+   }
+   return nil
 }
 
 func (f *flags) master() (*hls.Master, error) {
@@ -39,11 +72,15 @@ func (f *flags) master() (*hls.Master, error) {
    if err != nil {
       return nil, err
    }
+   if f.JSON != nil {
+      f.JSON.Metadata = asset
+   }
    media, err := profile.Media(asset)
    if err != nil {
       return nil, err
    }
    f.Name = asset.AppleContentId
+   f.Meta = asset.Meta()
    return f.HLS(*media.URL)
 }
 
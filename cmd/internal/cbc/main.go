@@ -0,0 +1,116 @@
+package cbc
+
+import (
+   "errors"
+   "flag"
+   "github.com/89z/mech"
+   "github.com/89z/mech/cbc"
+   "github.com/89z/mech/sink"
+   "os"
+)
+
+type flags struct {
+   bandwidth int64
+   email string
+   id string
+   json bool
+   mech.Stream
+   mux bool
+   name string
+   password string
+   remove bool
+   stdout bool
+   subtitle string
+   tar bool
+}
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("cbc", flag.ExitOnError)
+   var f flags
+   // b
+   set.StringVar(&f.id, "b", "", "ID")
+   // e
+   set.StringVar(&f.email, "e", "", "email")
+   // f
+   set.Int64Var(&f.bandwidth, "f", 2052370, "video bandwidth")
+   // g
+   set.StringVar(&f.name, "g", "English", "audio name")
+   // i
+   set.BoolVar(&f.Info, "i", false, "information")
+   // j
+   set.BoolVar(&f.json, "j", false, "JSON information")
+   // m
+   set.BoolVar(&f.mux, "m", false, "mux audio and video")
+   // o
+   set.Var(&f.Output, "o", "output template, such as {title}.{ext}")
+   // p
+   set.StringVar(&f.password, "p", "", "password")
+   // rate
+   set.Int64Var(&f.Rate, "rate", 0, "bytes per second, zero for no limit")
+   // resume
+   set.BoolVar(&f.Resume, "resume", false, "resume from checkpoint")
+   // retry
+   set.IntVar(&f.Retry.Attempts, "retry", 1, "attempts per request")
+   // rm
+   set.BoolVar(&f.remove, "rm", false, "remove the inputs after -m")
+   // s
+   set.StringVar(&f.subtitle, "s", "", "subtitle name")
+   // select
+   set.Var(&f.Select, "select", "track selector, such as video.height<=1080")
+   // srt
+   set.BoolVar(&f.SRT, "srt", false, "write subtitles as SubRip")
+   // stdout
+   set.BoolVar(&f.stdout, "stdout", false, "write media to standard output")
+   // tar
+   set.BoolVar(&f.tar, "tar", false, "write outputs to standard output as tar")
+   // verify
+   set.BoolVar(&f.Verify, "verify", false, "compare duration with manifest")
+   // w
+   set.IntVar(&f.Workers, "w", 1, "segments to fetch in parallel")
+   set.Parse(args)
+   if f.json {
+      f.Info = true
+      f.JSON = new(mech.Info_JSON)
+   }
+   if f.mux && (f.stdout || f.tar) {
+      return errors.New("-m needs files, not -stdout or -tar")
+   }
+   var tar *sink.Tar
+   if !f.Info {
+      if f.tar {
+         tar = sink.New_Tar(os.Stdout)
+         f.Sink = tar
+      } else if f.stdout {
+         f.Sink = sink.Stdout
+      }
+   }
+   if f.Retry.Attempts >= 2 {
+      cbc.Client = cbc.Client.Transport(f.Retry.Transport())
+   }
+   if f.email != "" {
+      err := f.profile()
+      if err != nil {
+         return err
+      }
+   } else if f.id != "" {
+      err := f.download()
+      if err != nil {
+         return err
+      }
+      if tar != nil {
+         err := tar.Close()
+         if err != nil {
+            return err
+         }
+      }
+      if f.JSON != nil {
+         err := f.JSON.Write(os.Stdout)
+         if err != nil {
+            return err
+         }
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
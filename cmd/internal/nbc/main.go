@@ -0,0 +1,78 @@
+package nbc
+
+import (
+   "flag"
+   "github.com/89z/mech"
+   "github.com/89z/mech/nbc"
+   "github.com/89z/mech/sink"
+   "os"
+)
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("nbc", flag.ExitOnError)
+   var f flags
+   set.StringVar(&f.archive, "archive", "", "download archive file")
+   set.Int64Var(&f.guid, "b", 0, "GUID")
+   set.StringVar(&f.cache, "cache", "", "segment cache directory for -serve")
+   set.Int64Var(&f.bandwidth, "f", 3_000_000, "target bandwidth")
+   set.BoolVar(&f.Info, "i", false, "information")
+   set.BoolVar(&f.json, "j", false, "JSON information")
+   set.Var(&f.Output, "o", "output template, such as {title}.{ext}")
+   set.Int64Var(&f.Rate, "rate", 0, "bytes per second, zero for no limit")
+   set.BoolVar(&f.Resume, "resume", false, "resume from checkpoint")
+   set.IntVar(&f.Retry.Attempts, "retry", 1, "attempts per request")
+   set.StringVar(&f.serve, "serve", "", "serve HLS on address, such as :8080")
+   set.StringVar(&f.subtitle, "s", "", "subtitle name")
+   set.Var(&f.Select, "select", "track selector, such as video.height<=1080")
+   set.BoolVar(&f.SRT, "srt", false, "write subtitles as SubRip")
+   set.BoolVar(&f.stdout, "stdout", false, "write media to standard output")
+   set.BoolVar(&f.Strip_Ads, "strip", false, "drop HLS ad breaks")
+   set.BoolVar(&f.tar, "tar", false, "write outputs to standard output as tar")
+   set.BoolVar(&f.verbose, "v", false, "verbose")
+   set.BoolVar(&f.Verify, "verify", false, "compare duration with manifest")
+   set.IntVar(&f.Workers, "w", 1, "segments to fetch in parallel")
+   set.Parse(args)
+   if f.json {
+      f.Info = true
+      f.JSON = new(mech.Info_JSON)
+   }
+   var tar *sink.Tar
+   if !f.Info {
+      if f.tar {
+         tar = sink.New_Tar(os.Stdout)
+         f.Sink = tar
+      } else if f.stdout {
+         f.Sink = sink.Stdout
+      }
+   }
+   if f.Sink != nil {
+      f.archive = ""
+   }
+   if f.Retry.Attempts >= 2 {
+      nbc.Client = nbc.Client.Transport(f.Retry.Transport())
+   }
+   if f.verbose {
+      nbc.Client.Log_Level = 2
+   }
+   if f.guid >= 1 {
+      err := f.download()
+      if err != nil {
+         return err
+      }
+      if tar != nil {
+         err := tar.Close()
+         if err != nil {
+            return err
+         }
+      }
+      if f.JSON != nil {
+         err := f.JSON.Write(os.Stdout)
+         if err != nil {
+            return err
+         }
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
@@ -0,0 +1,85 @@
+package nbc
+
+import (
+   "fmt"
+   "github.com/89z/mech"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/nbc"
+   "github.com/89z/rosso/hls"
+   "os"
+   "strconv"
+)
+
+type flags struct {
+   archive string
+   bandwidth int64
+   cache string
+   guid int64
+   json bool
+   mech.Stream
+   serve string
+   stdout bool
+   subtitle string
+   tar bool
+   verbose bool
+}
+
+func (f flags) download() error {
+   arc, err := meta.Open_Archive(f.archive)
+   if err != nil {
+      return err
+   }
+   defer arc.Close()
+   item := meta.Data{ID: strconv.FormatInt(f.guid, 10), Site: "nbc"}
+   if !f.Info && arc.Has(item) {
+      fmt.Fprintln(os.Stderr, "Skip", item.ID)
+      return nil
+   }
+   page, err := nbc.New_Bonanza_Page(f.guid)
+   if err != nil {
+      return err
+   }
+   video, err := page.Video()
+   if err != nil {
+      return err
+   }
+   if f.JSON != nil {
+      f.JSON.Metadata = page
+   }
+   f.Name = page.Analytics.ConvivaAssetName
+   f.Meta = page.Meta()
+   if f.serve != "" {
+      return f.Serve(video.ManifestPath, f.serve, f.cache)
+   }
+   master, err := f.HLS(video.ManifestPath)
+   if err != nil {
+      return err
+   }
+   subs := master.Media.Filter(func(m hls.Medium) bool {
+      return m.Type == "SUBTITLES"
+   })
+   if len(subs) >= 1 && (f.Info || f.subtitle != "") {
+      index := subs.Index(func(a, b hls.Medium) bool {
+         return b.Name == f.subtitle
+      })
+      index, err := f.HLS_Media_Index(subs, index)
+      if err != nil {
+         return err
+      }
+      if err := f.HLS_Subtitles(subs, index); err != nil {
+         return err
+      }
+   }
+   streams := master.Streams
+   index, err := f.HLS_Streams_Index(streams, streams.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   if err := f.HLS_Streams(streams, index); err != nil {
+      return err
+   }
+   if f.Info {
+      return nil
+   }
+   return arc.Add(page.Meta())
+}
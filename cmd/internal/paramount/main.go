@@ -0,0 +1,147 @@
+package paramount
+
+import (
+   "errors"
+   "flag"
+   "github.com/89z/mech"
+   "github.com/89z/mech/paramount"
+   "github.com/89z/mech/sink"
+   "github.com/89z/mech/widevine"
+   "os"
+   "path/filepath"
+)
+
+type flags struct {
+   bandwidth int64
+   codecs string
+   dash bool
+   guid string
+   json bool
+   lang string
+   mech.Stream
+   mux bool
+   remove bool
+   stdout bool
+   subtitle string
+   tar bool
+   verbose bool
+}
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("paramount", flag.ExitOnError)
+   home, err := os.UserHomeDir()
+   if err != nil {
+      return err
+   }
+   var f flags
+   // b
+   set.StringVar(&f.guid, "b", "", "GUID")
+   // c
+   f.Client_ID = filepath.Join(home, "mech/client_id.bin")
+   set.StringVar(&f.Client_ID, "c", f.Client_ID, "client ID")
+   // d
+   set.BoolVar(&f.dash, "d", false, "DASH download")
+   // f
+   set.Int64Var(&f.bandwidth, "f", 1_999_999, "video bandwidth")
+   // g
+   set.StringVar(&f.codecs, "g", "mp4a", "audio codec")
+   // h
+   set.StringVar(&f.lang, "h", "en", "audio lang")
+   // i
+   set.BoolVar(&f.Info, "i", false, "information")
+   // j
+   set.BoolVar(&f.json, "j", false, "JSON information")
+   // k
+   f.Private_Key = filepath.Join(home, "mech/private_key.pem")
+   set.StringVar(&f.Private_Key, "k", f.Private_Key, "private key")
+   // m
+   set.BoolVar(&f.mux, "m", false, "mux audio and video")
+   // o
+   set.Var(&f.Output, "o", "output template, such as {title}.{ext}")
+   // rate
+   set.Int64Var(&f.Rate, "rate", 0, "bytes per second, zero for no limit")
+   // resume
+   set.BoolVar(&f.Resume, "resume", false, "resume from checkpoint")
+   // retry
+   set.IntVar(&f.Retry.Attempts, "retry", 1, "attempts per request")
+   // rm
+   set.BoolVar(&f.remove, "rm", false, "remove the inputs after -m")
+   // s
+   set.StringVar(&f.subtitle, "s", "", "subtitle lang")
+   // select
+   set.Var(&f.Select, "select", "track selector, such as video.height<=1080")
+   // srt
+   set.BoolVar(&f.SRT, "srt", false, "write subtitles as SubRip")
+   // stdout
+   set.BoolVar(&f.stdout, "stdout", false, "write media to standard output")
+   // tar
+   set.BoolVar(&f.tar, "tar", false, "write outputs to standard output as tar")
+   // v
+   set.BoolVar(&f.verbose, "v", false, "verbose")
+   // verify
+   set.BoolVar(&f.Verify, "verify", false, "compare duration with manifest")
+   // w
+   set.IntVar(&f.Workers, "w", 1, "segments to fetch in parallel")
+   set.Parse(args)
+   if f.mux && !f.dash {
+      return errors.New("-m needs -d, as an HLS stream has audio and video")
+   }
+   if f.json {
+      f.Info = true
+      f.JSON = new(mech.Info_JSON)
+   }
+   if f.mux && (f.stdout || f.tar) {
+      return errors.New("-m needs files, not -stdout or -tar")
+   }
+   var tar *sink.Tar
+   if !f.Info {
+      if f.tar {
+         tar = sink.New_Tar(os.Stdout)
+         f.Sink = tar
+      } else if f.stdout {
+         f.Sink = sink.Stdout
+      }
+   }
+   if f.Retry.Attempts >= 2 {
+      paramount.Client = paramount.Client.Transport(f.Retry.Transport())
+   }
+   if f.verbose {
+      paramount.Client.Log_Level = 2
+      widevine.Client.Log_Level = 2
+   }
+   if f.guid != "" {
+      preview, err := paramount.New_Preview(f.guid)
+      if err != nil {
+         return err
+      }
+      if f.JSON != nil {
+         f.JSON.Metadata = preview
+      }
+      if f.dash {
+         err := f.DASH(preview)
+         if err != nil {
+            return err
+         }
+      } else {
+         err := f.HLS(preview)
+         if err != nil {
+            return err
+         }
+      }
+      if tar != nil {
+         err := tar.Close()
+         if err != nil {
+            return err
+         }
+      }
+      if f.JSON != nil {
+         err := f.JSON.Write(os.Stdout)
+         if err != nil {
+            return err
+         }
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
@@ -1,6 +1,7 @@
-package main
+package paramount
 
 import (
+   "github.com/89z/mech"
    "github.com/89z/mech/paramount"
    "github.com/89z/rosso/dash"
    "github.com/89z/rosso/hls"
@@ -14,6 +15,7 @@ func (f flags) DASH(preview *paramount.Preview) error {
       return err
    }
    f.Name = preview.Name()
+   f.Meta = preview.Meta()
    reps, err := f.Stream.DASH(paramount.DASH(f.guid))
    if err != nil {
       return err
@@ -36,15 +38,42 @@ func (f flags) DASH(preview *paramount.Preview) error {
       }
       return true
    })
+   index, err = f.DASH_Index(audio, index)
+   if err != nil {
+      return err
+   }
+   var tracks []mech.Track
+   f.Tracks = &tracks
    if err := f.DASH_Get(audio, index); err != nil {
       return err
    }
    video := reps.Video()
-   return f.DASH_Get(video, video.Bandwidth(f.bandwidth))
+   video_index, err := f.DASH_Index(video, video.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   if err := f.DASH_Get(video, video_index); err != nil {
+      return err
+   }
+   text := mech.Text(reps)
+   if len(text) >= 1 && (f.Info || f.subtitle != "") {
+      index, err := f.DASH_Index(text, mech.Text_Index(text, f.subtitle, ""))
+      if err != nil {
+         return err
+      }
+      if err := f.DASH_Text(text, index); err != nil {
+         return err
+      }
+   }
+   if f.mux && !f.Info {
+      return f.Mux(tracks, f.remove)
+   }
+   return nil
 }
 
 func (f flags) HLS(preview *paramount.Preview) error {
    f.Name = preview.Name()
+   f.Meta = preview.Meta()
    master, err := f.Stream.HLS(paramount.HLS(f.guid))
    if err != nil {
       return err
@@ -52,5 +81,9 @@ func (f flags) HLS(preview *paramount.Preview) error {
    streams := master.Streams.Filter(func(s hls.Stream) bool {
       return s.Resolution != ""
    })
-   return f.HLS_Streams(streams, streams.Bandwidth(f.bandwidth))
+   index, err := f.HLS_Streams_Index(streams, streams.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   return f.HLS_Streams(streams, index)
 }
@@ -0,0 +1,167 @@
+package roku
+
+import (
+   "errors"
+   "flag"
+   "fmt"
+   "github.com/89z/mech"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/roku"
+   "github.com/89z/mech/sink"
+   "os"
+   "path/filepath"
+)
+
+type flags struct {
+   archive string
+   bandwidth int64
+   cache string
+   codec string
+   dash bool
+   id string
+   json bool
+   mech.Stream
+   mux bool
+   remove bool
+   serve string
+   stdout bool
+   subtitle string
+   tar bool
+}
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("roku", flag.ExitOnError)
+   home, err := os.UserHomeDir()
+   if err != nil {
+      return err
+   }
+   var f flags
+   // archive
+   set.StringVar(&f.archive, "archive", "", "download archive file")
+   // b
+   set.StringVar(&f.id, "b", "", "ID")
+   // c
+   f.Client_ID = filepath.Join(home, "mech/client_id.bin")
+   set.StringVar(&f.Client_ID, "c", f.Client_ID, "client ID")
+   // cache
+   set.StringVar(&f.cache, "cache", "", "segment cache directory for -serve")
+   // d
+   set.BoolVar(&f.dash, "d", false, "DASH download")
+   // f
+   set.Int64Var(&f.bandwidth, "f", 1920832, "video bandwidth")
+   // g
+   set.StringVar(&f.codec, "g", "mp4a", "audio codec")
+   // i
+   set.BoolVar(&f.Info, "i", false, "information")
+   // j
+   set.BoolVar(&f.json, "j", false, "JSON information")
+   // k
+   f.Private_Key = filepath.Join(home, "mech/private_key.pem")
+   set.StringVar(&f.Private_Key, "k", f.Private_Key, "private key")
+   // m
+   set.BoolVar(&f.mux, "m", false, "mux audio and video")
+   // o
+   set.Var(&f.Output, "o", "output template, such as {title}.{ext}")
+   // rate
+   set.Int64Var(&f.Rate, "rate", 0, "bytes per second, zero for no limit")
+   // resume
+   set.BoolVar(&f.Resume, "resume", false, "resume from checkpoint")
+   // retry
+   set.IntVar(&f.Retry.Attempts, "retry", 1, "attempts per request")
+   // rm
+   set.BoolVar(&f.remove, "rm", false, "remove the inputs after -m")
+   // s
+   set.StringVar(&f.subtitle, "s", "", "subtitle name, or lang with DASH")
+   // select
+   set.Var(&f.Select, "select", "track selector, such as video.height<=1080")
+   // serve
+   set.StringVar(&f.serve, "serve", "", "serve HLS on address, such as :8080")
+   // srt
+   set.BoolVar(&f.SRT, "srt", false, "write subtitles as SubRip")
+   // stdout
+   set.BoolVar(&f.stdout, "stdout", false, "write media to standard output")
+   // strip
+   set.BoolVar(&f.Strip_Ads, "strip", false, "drop HLS ad breaks")
+   // tar
+   set.BoolVar(&f.tar, "tar", false, "write outputs to standard output as tar")
+   // verify
+   set.BoolVar(&f.Verify, "verify", false, "compare duration with manifest")
+   // w
+   set.IntVar(&f.Workers, "w", 1, "segments to fetch in parallel")
+   set.Parse(args)
+   if f.mux && !f.dash {
+      return errors.New("-m needs -d, as an HLS stream has audio and video")
+   }
+   if f.json {
+      f.Info = true
+      f.JSON = new(mech.Info_JSON)
+   }
+   if f.mux && (f.stdout || f.tar) {
+      return errors.New("-m needs files, not -stdout or -tar")
+   }
+   var tar *sink.Tar
+   if !f.Info {
+      if f.tar {
+         tar = sink.New_Tar(os.Stdout)
+         f.Sink = tar
+      } else if f.stdout {
+         f.Sink = sink.Stdout
+      }
+   }
+   if f.Sink != nil {
+      f.archive = ""
+   }
+   if f.Retry.Attempts >= 2 {
+      roku.Client = roku.Client.Transport(f.Retry.Transport())
+   }
+   if f.id != "" {
+      arc, err := meta.Open_Archive(f.archive)
+      if err != nil {
+         return err
+      }
+      defer arc.Close()
+      content, err := roku.New_Content(f.id)
+      if err != nil {
+         return err
+      }
+      if !f.Info && arc.Has(content.Get_Meta()) {
+         fmt.Fprintln(os.Stderr, "Skip", content.Meta.ID)
+         return nil
+      }
+      if f.JSON != nil {
+         f.JSON.Metadata = content
+      }
+      if f.dash {
+         err := f.DASH(content)
+         if err != nil {
+            return err
+         }
+      } else {
+         err := f.HLS(content)
+         if err != nil {
+            return err
+         }
+      }
+      if !f.Info {
+         err := arc.Add(content.Get_Meta())
+         if err != nil {
+            return err
+         }
+      }
+      if tar != nil {
+         err := tar.Close()
+         if err != nil {
+            return err
+         }
+      }
+      if f.JSON != nil {
+         err := f.JSON.Write(os.Stdout)
+         if err != nil {
+            return err
+         }
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
@@ -0,0 +1,98 @@
+package roku
+
+import (
+   "github.com/89z/mech"
+   "github.com/89z/mech/roku"
+   "github.com/89z/rosso/dash"
+   "github.com/89z/rosso/hls"
+   "strings"
+)
+
+func (f flags) DASH(content *roku.Content) error {
+   site, err := roku.New_Cross_Site()
+   if err != nil {
+      return err
+   }
+   f.Poster, err = site.Playback(f.id)
+   if err != nil {
+      return err
+   }
+   f.Name = content.Name()
+   f.Meta = content.Get_Meta()
+   reps, err := f.Stream.DASH(content.DASH().URL)
+   if err != nil {
+      return err
+   }
+   audio := reps.Audio()
+   index := audio.Index(func(a, b dash.Representation) bool {
+      return strings.Contains(b.Codecs, f.codec)
+   })
+   index, err = f.DASH_Index(audio, index)
+   if err != nil {
+      return err
+   }
+   var tracks []mech.Track
+   f.Tracks = &tracks
+   if err := f.DASH_Get(audio, index); err != nil {
+      return err
+   }
+   video := reps.Video()
+   video_index, err := f.DASH_Index(video, video.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   if err := f.DASH_Get(video, video_index); err != nil {
+      return err
+   }
+   text := mech.Text(reps)
+   if len(text) >= 1 && (f.Info || f.subtitle != "") {
+      index, err := f.DASH_Index(text, mech.Text_Index(text, f.subtitle, ""))
+      if err != nil {
+         return err
+      }
+      if err := f.DASH_Text(text, index); err != nil {
+         return err
+      }
+   }
+   if f.mux && !f.Info {
+      return f.Mux(tracks, f.remove)
+   }
+   return nil
+}
+
+func (f flags) HLS(content *roku.Content) error {
+   video, err := content.HLS()
+   if err != nil {
+      return err
+   }
+   f.Name = content.Name()
+   f.Meta = content.Get_Meta()
+   if f.serve != "" {
+      return f.Serve(video.URL, f.serve, f.cache)
+   }
+   master, err := f.Stream.HLS(video.URL)
+   if err != nil {
+      return err
+   }
+   subs := master.Media.Filter(func(m hls.Medium) bool {
+      return m.Type == "SUBTITLES"
+   })
+   if len(subs) >= 1 && (f.Info || f.subtitle != "") {
+      index := subs.Index(func(a, b hls.Medium) bool {
+         return b.Name == f.subtitle
+      })
+      index, err := f.HLS_Media_Index(subs, index)
+      if err != nil {
+         return err
+      }
+      if err := f.HLS_Subtitles(subs, index); err != nil {
+         return err
+      }
+   }
+   streams := master.Streams
+   index, err := f.HLS_Streams_Index(streams, streams.Bandwidth(f.bandwidth))
+   if err != nil {
+      return err
+   }
+   return f.HLS_Streams(streams, index)
+}
@@ -0,0 +1,71 @@
+package soundcloud
+
+import (
+   "flag"
+   "fmt"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/soundcloud"
+   "os"
+   "time"
+)
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("soundcloud", flag.ExitOnError)
+   // a
+   var address string
+   set.StringVar(&address, "a", "", "address")
+   // archive
+   var archive string
+   set.StringVar(&archive, "archive", "", "download archive file")
+   // i
+   var info bool
+   set.BoolVar(&info, "i", false, "information")
+   // o
+   var output meta.Template
+   output.Set("{author}-{title}.{ext}")
+   set.Var(&output, "o", "output template")
+   // rate
+   var limit int64
+   set.Int64Var(&limit, "rate", 0, "bytes per second, zero for no limit")
+   // s
+   var sleep time.Duration
+   set.DurationVar(&sleep, "s", time.Second, "sleep")
+   // v
+   set.Parse(args)
+   rate.Global = rate.New_Limiter(limit)
+   if address != "" {
+      arc, err := meta.Open_Archive(archive)
+      if err != nil {
+         return err
+      }
+      defer arc.Close()
+      tracks, err := soundcloud.Resolve(address)
+      if err != nil {
+         return err
+      }
+      var downloads int
+      for _, track := range tracks {
+         if info {
+            fmt.Println(track)
+         } else if arc.Has(track.Meta()) {
+            fmt.Fprintln(os.Stderr, "Skip", track.ID)
+         } else {
+            if downloads >= 1 {
+               time.Sleep(sleep)
+            }
+            downloads++
+            err := download(track, output)
+            if err != nil {
+               return err
+            }
+            if err := arc.Add(track.Meta()); err != nil {
+               return err
+            }
+         }
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
@@ -0,0 +1,42 @@
+package soundcloud
+
+import (
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/progress"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/soundcloud"
+   "github.com/89z/mech/verify"
+   "io"
+)
+
+func download(track soundcloud.Track, output meta.Template) error {
+   media, err := track.Progressive()
+   if err != nil {
+      return err
+   }
+   res, err := soundcloud.Client.Redirect(nil).Get(media.URL)
+   if err != nil {
+      return err
+   }
+   defer res.Body.Close()
+   ext, err := media.Ext()
+   if err != nil {
+      return err
+   }
+   name := output.Execute(track.Meta(), ext)
+   file, err := meta.Create_Part(name)
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   pro := progress.New_Bytes(file, nil, name, res.ContentLength)
+   dst := rate.New_Writer(pro, rate.Global)
+   size, err := io.Copy(dst, res.Body)
+   if err == nil {
+      err = verify.Length(res.Request.URL.String(), res.ContentLength, size)
+   }
+   if err := pro.Finish(err); err != nil {
+      return err
+   }
+   return file.Commit()
+}
@@ -0,0 +1,57 @@
+package twitter
+
+import (
+   "flag"
+   "github.com/89z/mech"
+   "github.com/89z/mech/twitter"
+   "os"
+)
+
+type flags struct {
+   address string
+   json bool
+   mech.Stream
+   verbose bool
+}
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("twitter", flag.ExitOnError)
+   var f flags
+   // a
+   set.StringVar(&f.address, "a", "", "address")
+   // d
+   set.DurationVar(&f.Live_Duration, "d", 0, "live duration")
+   // i
+   set.BoolVar(&f.Info, "i", false, "information")
+   // j
+   set.BoolVar(&f.json, "j", false, "JSON information")
+   // o
+   set.Var(&f.Output, "o", "output template, such as {title}.{ext}")
+   // rate
+   set.Int64Var(&f.Rate, "rate", 0, "bytes per second, zero for no limit")
+   // v
+   set.BoolVar(&f.verbose, "v", false, "verbose")
+   set.Parse(args)
+   if f.json {
+      f.Info = true
+      f.JSON = new(mech.Info_JSON)
+   }
+   if f.verbose {
+      twitter.Client.Log_Level = 2
+   }
+   if f.address != "" {
+      err := f.download()
+      if err != nil {
+         return err
+      }
+      if f.JSON != nil {
+         err := f.JSON.Write(os.Stdout)
+         if err != nil {
+            return err
+         }
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
@@ -0,0 +1,42 @@
+package twitter
+
+import (
+   "context"
+   "fmt"
+   "github.com/89z/mech/twitter"
+   "os"
+   "os/signal"
+)
+
+func (f flags) download() error {
+   id, err := twitter.SpaceID(f.address)
+   if err != nil {
+      return err
+   }
+   guest, err := twitter.New_Guest()
+   if err != nil {
+      return err
+   }
+   space, err := guest.Audio_Space(id)
+   if err != nil {
+      return err
+   }
+   if f.JSON != nil {
+      f.JSON.Metadata = space
+   } else if f.Info {
+      fmt.Println(space)
+      return nil
+   }
+   source, err := guest.Source(space)
+   if err != nil {
+      return err
+   }
+   f.Name = space.Base()
+   f.Meta = space.Meta()
+   // a Space that has not ended is still adding segments
+   f.Live = space.Metadata.Ended_At == 0
+   // interrupt ends a recording with what it has
+   ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+   defer stop()
+   return f.HLS_Playlist_Context(ctx, source.Location)
+}
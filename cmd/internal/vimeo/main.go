@@ -0,0 +1,51 @@
+package vimeo
+
+import (
+   "flag"
+   "github.com/89z/mech"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/vimeo"
+   "strings"
+)
+
+type flags struct {
+   address string
+   height int64
+   info bool
+   output meta.Template
+   rate int64
+   selector mech.Selector
+   verbose bool
+}
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("vimeo", flag.ExitOnError)
+   var f flags
+   set.StringVar(&f.address, "a", "", "address")
+   set.Int64Var(&f.height, "f", 720, "target height")
+   set.BoolVar(&f.info, "i", false, "info only")
+   set.Var(&f.output, "o", "output template, such as {title}.{ext}")
+   set.Int64Var(&f.rate, "rate", 0, "bytes per second, zero for no limit")
+   set.Var(&f.selector, "select", "track selector, such as video.height<=1080")
+   set.BoolVar(&f.verbose, "v", false, "verbose")
+   set.Parse(args)
+   rate.Global = rate.New_Limiter(f.rate)
+   if f.verbose {
+      vimeo.Client.Log_Level = 2
+   }
+   if strings.Contains(f.address, "vimeo.com/") {
+      err := f.vimeo()
+      if err != nil {
+         return err
+      }
+   } else if vimeo.Is_Embed(f.address) {
+      err := f.vhx()
+      if err != nil {
+         return err
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
@@ -0,0 +1,123 @@
+package vimeo
+
+import (
+   "fmt"
+   "github.com/89z/mech"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/progress"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/verify"
+   "github.com/89z/mech/vimeo"
+   "io"
+   "net/url"
+   "path"
+   "strconv"
+)
+
+// pick returns the width and height that -select chooses, or else the first
+// with the target height, or -1
+func (f flags) pick(sizes [][2]int64) (int, error) {
+   index := -1
+   var items []mech.Info_Item
+   for i, size := range sizes {
+      if index == -1 && size[1] == f.height {
+         index = i
+      }
+      items = append(items, mech.Info_Item{
+         Mime_Type: "video/mp4",
+         Resolution: strconv.FormatInt(size[0], 10) + "x" +
+            strconv.FormatInt(size[1], 10),
+      })
+   }
+   return f.selector.Index(items, index)
+}
+
+func (f flags) vimeo() error {
+   web, err := vimeo.New_JSON_Web()
+   if err != nil {
+      return err
+   }
+   clip, err := vimeo.New_Clip(f.address)
+   if err != nil {
+      return err
+   }
+   video, err := web.Video(clip)
+   if err != nil {
+      return err
+   }
+   if f.info {
+      fmt.Println(video)
+   } else {
+      var sizes [][2]int64
+      for _, down := range video.Download {
+         sizes = append(sizes, [2]int64{down.Width, down.Height})
+      }
+      index, err := f.pick(sizes)
+      if err != nil {
+         return err
+      }
+      if index >= 0 {
+         return f.download(video.Download[index].Link, video.Meta())
+      }
+   }
+   return nil
+}
+
+func (f flags) vhx() error {
+   embed, err := vimeo.New_Embed(f.address)
+   if err != nil {
+      return err
+   }
+   config, err := embed.Config()
+   if err != nil {
+      return err
+   }
+   if f.info {
+      fmt.Println(config)
+   } else {
+      pros := config.Request.Files.Progressive
+      var sizes [][2]int64
+      for _, pro := range pros {
+         sizes = append(sizes, [2]int64{pro.Width, pro.Height})
+      }
+      index, err := f.pick(sizes)
+      if err != nil {
+         return err
+      }
+      if index >= 0 {
+         return f.download(pros[index].URL, config.Meta())
+      }
+   }
+   return nil
+}
+
+func (f flags) download(address string, data meta.Data) error {
+   res, err := vimeo.Client.Redirect(nil).Get(address)
+   if err != nil {
+      return err
+   }
+   defer res.Body.Close()
+   addr, err := url.Parse(address)
+   if err != nil {
+      return err
+   }
+   name := path.Base(addr.Path)
+   if f.output.Is_Set() {
+      name = f.output.Execute(data, path.Ext(addr.Path))
+   }
+   file, err := meta.Create_Part(name)
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   pro := progress.New_Bytes(file, nil, name, res.ContentLength)
+   dst := rate.New_Writer(pro, rate.Global)
+   size, err := io.Copy(dst, res.Body)
+   if err == nil {
+      err = verify.Length(res.Request.URL.String(), res.ContentLength, size)
+   }
+   if err := pro.Finish(err); err != nil {
+      return err
+   }
+   return file.Commit()
+}
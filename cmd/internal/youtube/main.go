@@ -0,0 +1,105 @@
+package youtube
+
+import (
+   "errors"
+   "flag"
+   "github.com/89z/mech"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/youtube"
+   "strings"
+)
+
+type flags struct {
+   access bool
+   archive string
+   audio string
+   height int
+   info bool
+   json bool
+   mux bool
+   output meta.Template
+   refresh bool
+   remove bool
+   request int
+   selector mech.Selector
+   stdout bool
+   verbose bool
+   video_ID string
+}
+
+func Main(args []string) error {
+   set := flag.NewFlagSet("youtube", flag.ExitOnError)
+   var f flags
+   // archive
+   set.StringVar(&f.archive, "archive", "", "download archive file")
+   // b
+   set.StringVar(&f.video_ID, "b", "", "video ID")
+   // f
+   set.IntVar(&f.height, "f", 1080, "target video height")
+   // g
+   set.StringVar(&f.audio, "g", "AUDIO_QUALITY_MEDIUM", "target audio")
+   // i
+   set.BoolVar(&f.info, "i", false, "information")
+   // j
+   set.BoolVar(&f.json, "j", false, "JSON information")
+   // m
+   set.BoolVar(&f.mux, "m", false, "mux MP4 audio and video")
+   // o
+   f.output.Set("{author}-{title}.{ext}")
+   set.Var(&f.output, "o", "output template")
+   // select
+   set.Var(&f.selector, "select", "track selector, such as video.height<=1080")
+   // stdout
+   set.BoolVar(&f.stdout, "stdout", false, "write one format to standard output")
+   // rate
+   set.Int64Var(&youtube.Rate, "rate", 0, "bytes per second, zero for no limit")
+   // refresh
+   set.BoolVar(&f.refresh, "refresh", false, "create OAuth refresh token")
+   // rm
+   set.BoolVar(&f.remove, "rm", false, "remove the inputs after -m")
+   // access
+   set.BoolVar(&f.access, "access", false, "create OAuth access token")
+   // r
+   var buf strings.Builder
+   buf.WriteString("0: Android\n")
+   buf.WriteString("1: Android embed\n")
+   buf.WriteString("2: Android racy\n")
+   buf.WriteString("3: Android content")
+   set.IntVar(&f.request, "r", 0, buf.String())
+   // a
+   set.Func("a", "address", func(s string) error {
+      return youtube.Video_ID(s, &f.video_ID)
+   })
+   // v
+   set.BoolVar(&f.verbose, "v", false, "verbose")
+   set.Parse(args)
+   if f.verbose {
+      youtube.HTTP_Client.Log_Level = 2
+   }
+   if f.refresh {
+      err := refresh()
+      if err != nil {
+         return err
+      }
+   } else if f.access {
+      err := access()
+      if err != nil {
+         return err
+      }
+   } else if f.video_ID != "" {
+      // stdout holds one stream, and nothing is left to mux
+      if f.stdout && f.mux {
+         return errors.New("-stdout cannot be used with -m")
+      }
+      if f.stdout && (f.height >= 1) == (f.audio != "") {
+         return errors.New(`-stdout writes one format, so set -f 0 or -g ""`)
+      }
+      err := f.download()
+      if err != nil {
+         return err
+      }
+   } else {
+      set.Usage()
+   }
+   return nil
+}
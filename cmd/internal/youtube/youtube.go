@@ -0,0 +1,211 @@
+package youtube
+
+import (
+   "fmt"
+   "github.com/89z/mech"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/sink"
+   "github.com/89z/mech/youtube"
+   "github.com/89z/rosso/os"
+   "io"
+   "mime"
+   "strconv"
+   "strings"
+)
+
+// encode returns the name that form was written to
+func (f flags) encode(
+   form *youtube.Format, play *youtube.Player,
+) (string, error) {
+   ext, err := form.Ext()
+   if err != nil {
+      return "", err
+   }
+   var out sink.Sink = sink.File{}
+   if f.stdout {
+      out = sink.Stdout
+   }
+   name := f.output.Execute(play.Meta(), ext)
+   if err := form.Encode_Sink(out, name); err != nil {
+      return "", err
+   }
+   return name, nil
+}
+
+// pick returns the format that -select chooses from those of kind, or form
+// if -select is unset
+func (f flags) pick(
+   forms youtube.Formats, kind string, form *youtube.Format, ok bool,
+) (*youtube.Format, bool, error) {
+   var (
+      items []mech.Info_Item
+      kinds youtube.Formats
+   )
+   for _, form := range forms {
+      media, param, err := mime.ParseMediaType(form.MimeType)
+      if err != nil {
+         return nil, false, err
+      }
+      if !strings.HasPrefix(media, kind) {
+         continue
+      }
+      item := mech.Info_Item{
+         Bandwidth: form.Bitrate,
+         Codecs: param["codecs"],
+         Mime_Type: media,
+      }
+      if form.Width >= 1 {
+         item.Resolution = strconv.Itoa(form.Width) + "x" +
+            strconv.Itoa(form.Height)
+      }
+      items = append(items, item)
+      kinds = append(kinds, form)
+   }
+   index, err := f.selector.Index(items, -1)
+   if err != nil {
+      return nil, false, err
+   }
+   if index == -1 {
+      return form, ok, nil
+   }
+   return &kinds[index], true, nil
+}
+
+// log is where status goes, which with -stdout is not with the media
+func (f flags) log() io.Writer {
+   if f.stdout {
+      return os.Stderr
+   }
+   return os.Stdout
+}
+
+func (f flags) download() error {
+   archive := f.archive
+   if f.stdout {
+      archive = "" // nothing is saved, so nothing is recorded
+   }
+   arc, err := meta.Open_Archive(archive)
+   if err != nil {
+      return err
+   }
+   defer arc.Close()
+   item := meta.Data{ID: f.video_ID, Site: "youtube"}
+   if !f.info && !f.json && arc.Has(item) {
+      fmt.Fprintln(f.log(), "Skip", item.ID)
+      return nil
+   }
+   play, err := f.player()
+   if err != nil {
+      return err
+   }
+   forms := play.StreamingData.AdaptiveFormats
+   if f.json {
+      info := mech.Info_JSON{Metadata: play}
+      return info.Write(os.Stdout)
+   }
+   if f.info {
+      text, err := play.MarshalText()
+      if err != nil {
+         return err
+      }
+      os.Stdout.Write(text)
+   } else {
+      fmt.Fprintln(f.log(), play.PlayabilityStatus)
+      var tracks []mech.Track
+      if f.height >= 1 {
+         form, ok := forms.Video(f.height)
+         form, ok, err := f.pick(forms, "video/", form, ok)
+         if err != nil {
+            return err
+         }
+         if ok {
+            name, err := f.encode(form, play)
+            if err != nil {
+               return err
+            }
+            tracks = append(tracks, mech.Track{Name: name})
+         }
+      }
+      if f.audio != "" {
+         form, ok := forms.Audio(f.audio)
+         form, ok, err := f.pick(forms, "audio/", form, ok)
+         if err != nil {
+            return err
+         }
+         if ok {
+            name, err := f.encode(form, play)
+            if err != nil {
+               return err
+            }
+            tracks = append(tracks, mech.Track{Name: name})
+         }
+      }
+      if f.mux {
+         var str mech.Stream
+         str.Meta = play.Meta()
+         str.Output = f.output
+         if err := str.Mux(tracks, f.remove); err != nil {
+            return err
+         }
+      }
+      return arc.Add(item)
+   }
+   return nil
+}
+
+func refresh() error {
+   auth, err := youtube.New_OAuth()
+   if err != nil {
+      return err
+   }
+   fmt.Println(auth)
+   fmt.Scanln()
+   head, err := auth.Header()
+   if err != nil {
+      return err
+   }
+   home, err := os.UserHomeDir()
+   if err != nil {
+      return err
+   }
+   return head.Create(home + "/mech/youtube.json")
+}
+
+func access() error {
+   home, err := os.UserHomeDir()
+   if err != nil {
+      return err
+   }
+   head, err := youtube.Open_Header(home + "/mech/youtube.json")
+   if err != nil {
+      return err
+   }
+   if err := head.Refresh(); err != nil {
+      return err
+   }
+   return head.Create(home + "/mech/youtube.json")
+}
+
+func (f flags) player() (*youtube.Player, error) {
+   var req youtube.Request
+   if f.request == 0 {
+      req = youtube.Android()
+   } else if f.request == 1 {
+      req = youtube.Android_Embed()
+   } else {
+      if f.request == 2 {
+         req = youtube.Android_Racy()
+      } else {
+         req = youtube.Android_Content()
+      }
+      home, err := os.UserHomeDir()
+      if err != nil {
+         return nil, err
+      }
+      req.Header, err = youtube.Open_Header(home + "/mech/youtube.json")
+      if err != nil {
+         return nil, err
+      }
+   }
+   return req.Player(f.video_ID)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+   "fmt"
+   "github.com/89z/mech/cmd/internal/amc"
+   "github.com/89z/mech/cmd/internal/cbc"
+   "github.com/89z/mech/cmd/internal/nbc"
+   "github.com/89z/mech/cmd/internal/paramount"
+   "github.com/89z/mech/cmd/internal/roku"
+   "github.com/89z/mech/cmd/internal/soundcloud"
+   "github.com/89z/mech/cmd/internal/twitter"
+   "github.com/89z/mech/cmd/internal/vimeo"
+   "github.com/89z/mech/cmd/internal/youtube"
+   "github.com/89z/mech/extractor"
+   "os"
+   "strings"
+)
+
+// command is how an extractor is run. The ID, or the whole address if
+// address is set, goes to flag.
+type command struct {
+   main func([]string) error
+   flag string
+   address bool
+}
+
+var commands = map[string]command{
+   "amc": {main: amc.Main, flag: "-b"},
+   "cbc": {main: cbc.Main, flag: "-b"},
+   "nbc": {main: nbc.Main, flag: "-b"},
+   "paramount": {main: paramount.Main, flag: "-b"},
+   "roku": {main: roku.Main, flag: "-b"},
+   "soundcloud": {main: soundcloud.Main, flag: "-a", address: true},
+   "twitter": {main: twitter.Main, flag: "-a", address: true},
+   "vimeo": {main: vimeo.Main, flag: "-a", address: true},
+   "youtube": {main: youtube.Main, flag: "-b"},
+}
+
+func usage() {
+   fmt.Fprintln(os.Stderr, "usage: mech address [site flags]")
+   fmt.Fprintln(os.Stderr, "\nsites:")
+   for _, ext := range extractor.Extractors() {
+      fmt.Fprintf(os.Stderr, "  %v\t%v\n", ext.Name, strings.Join(ext.Hosts, ", "))
+   }
+}
+
+func main() {
+   if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+      usage()
+      os.Exit(2)
+   }
+   if err := run(os.Args[1], os.Args[2:]); err != nil {
+      panic(err)
+   }
+}
+
+func run(address string, args []string) error {
+   ext, id, err := extractor.Find(address)
+   if err != nil {
+      return err
+   }
+   cmd, ok := commands[ext.Name]
+   if !ok {
+      return fmt.Errorf("no command for %v", ext.Name)
+   }
+   if cmd.address {
+      id = address
+      if !strings.Contains(id, "://") {
+         id = "https://" + id
+      }
+   }
+   return cmd.main(append([]string{cmd.flag, id}, args...))
+}
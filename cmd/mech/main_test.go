@@ -0,0 +1,38 @@
+package main
+
+import (
+   "github.com/89z/mech/extractor"
+   "testing"
+)
+
+var addresses = []struct {
+   address, name, id string
+}{
+   {"https://www.amcplus.com/shows/orphan-black/episodes/season-1-instinct--1011152", "amc", "1011152"},
+   {"gem.cbc.ca/media/downton-abbey/s01e05", "cbc", "downton-abbey/s01e05"},
+   {"https://www.nbc.com/la-brea/video/pilot/9000194212", "nbc", "9000194212"},
+   {"https://www.paramountplus.com/shows/video/eyT_RYkqNuH_6ZYrepLtxkiPO1HA7dIU/", "paramount", "eyT_RYkqNuH_6ZYrepLtxkiPO1HA7dIU"},
+   {"https://therokuchannel.roku.com/watch/105c41ea75775968b670fbb26978ed76", "roku", "105c41ea75775968b670fbb26978ed76"},
+   {"https://soundcloud.com/kino-scmusic", "soundcloud", "/kino-scmusic"},
+   {"https://twitter.com/i/spaces/1jMJgednpreKL?s=20", "twitter", "1jMJgednpreKL"},
+   {"https://vimeo.com/66531465", "vimeo", "66531465"},
+   {"https://www.youtube.com/watch?v=UpNXI3_ctAc", "youtube", "UpNXI3_ctAc"},
+   {"https://youtu.be/UpNXI3_ctAc", "youtube", "UpNXI3_ctAc"},
+}
+
+func Test_Find(t *testing.T) {
+   for _, test := range addresses {
+      ext, id, err := extractor.Find(test.address)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if ext.Name != test.name || id != test.id {
+         t.Fatal(test.address, ext.Name, id)
+      }
+   }
+   for _, ext := range extractor.Extractors() {
+      if _, ok := commands[ext.Name]; !ok {
+         t.Fatal(ext.Name)
+      }
+   }
+}
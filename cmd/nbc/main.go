@@ -1,26 +1,12 @@
 package main
 
 import (
-   "flag"
-   "github.com/89z/mech/nbc"
+   "github.com/89z/mech/cmd/internal/nbc"
+   "os"
 )
 
 func main() {
-   var f flags
-   flag.Int64Var(&f.guid, "b", 0, "GUID")
-   flag.Int64Var(&f.bandwidth, "f", 3_000_000, "target bandwidth")
-   flag.BoolVar(&f.Info, "i", false, "information")
-   flag.BoolVar(&f.verbose, "v", false, "verbose")
-   flag.Parse()
-   if f.verbose {
-      nbc.Client.Log_Level = 2
-   }
-   if f.guid >= 1 {
-      err := f.download()
-      if err != nil {
-         panic(err)
-      }
-   } else {
-      flag.Usage()
+   if err := nbc.Main(os.Args[1:]); err != nil {
+      panic(err)
    }
 }
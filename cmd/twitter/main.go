@@ -0,0 +1,12 @@
+package main
+
+import (
+   "github.com/89z/mech/cmd/internal/twitter"
+   "os"
+)
+
+func main() {
+   if err := twitter.Main(os.Args[1:]); err != nil {
+      panic(err)
+   }
+}
@@ -1,8 +1,15 @@
 package mech
 
 import (
+   "bytes"
+   "context"
    "encoding/xml"
+   "errors"
    "fmt"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/progress"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/sink"
    "github.com/89z/mech/widevine"
    "github.com/89z/rosso/dash"
    "github.com/89z/rosso/http"
@@ -10,35 +17,161 @@ import (
    "github.com/89z/rosso/os"
    "io"
    "net/url"
+   "strconv"
+   "strings"
+   "time"
 )
 
 var client = http.Default_Client
 
+// retry is Retry, with the Progress of this download if it has none
+func (s Stream) retry() Retry {
+   retry := s.Retry
+   if retry.Progress == nil {
+      retry.Progress = s.Progress
+   }
+   return retry
+}
+
+func (s Stream) http_client() http.Client {
+   if s.Retry.Attempts >= 2 {
+      return client.Transport(s.retry().Transport())
+   }
+   return client
+}
+
+// limiters are rate.Global, and the Rate of this download. They are applied
+// as segments are read, so that fetch workers are held back too.
+func (s Stream) limiters() []*rate.Limiter {
+   return []*rate.Limiter{rate.Global, rate.New_Limiter(s.Rate)}
+}
+
+// mpd keeps what rosso dash drops: BaseURL, which sidecar text tracks and
+// SegmentBase use, frame rate and duration
+type mpd struct {
+   Duration string `xml:"mediaPresentationDuration,attr"`
+   Period struct {
+      AdaptationSet []struct {
+         FrameRate string `xml:"frameRate,attr"`
+         Representation []mpd_representation
+         SegmentBase *segment_base
+      }
+   }
+}
+
+type mpd_representation struct {
+   BaseURL string
+   FrameRate string `xml:"frameRate,attr"`
+   ID string `xml:"id,attr"`
+   SegmentBase *segment_base
+}
+
+func (m mpd) representations() map[string]mpd_representation {
+   reps := make(map[string]mpd_representation)
+   for _, ada := range m.Period.AdaptationSet {
+      for _, rep := range ada.Representation {
+         rep.BaseURL = strings.TrimSpace(rep.BaseURL)
+         if rep.FrameRate == "" {
+            rep.FrameRate = ada.FrameRate
+         }
+         if rep.SegmentBase == nil {
+            rep.SegmentBase = ada.SegmentBase
+         }
+         reps[rep.ID] = rep
+      }
+   }
+   return reps
+}
+
 type Stream struct {
    Client_ID string
    Info bool
+   JSON *Info_JSON // with Info, items are added here instead of printed
+   Live bool
+   Live_Duration time.Duration // zero records until the playlist ends
+   Meta meta.Data // used by Output
    Private_Key string
+   Progress progress.Observer // nil for progress.Default
+   Rate int64 // bytes per second for each download, zero for no limit
    Poster widevine.Poster
    Name string
+   Output meta.Template // file names, when set, instead of Name
+   Resume bool
+   Retry Retry
+   Select Selector // used by the _Index methods
+   // where downloads are written, nil for files. Resume and Verify need
+   // files, and Mux needs files for its inputs.
+   Sink sink.Sink
+   SRT bool // write subtitles as SubRip
+   // drop the ad breaks of HLS VOD, and list them with Info. With Resume,
+   // timestamps after a break are only moved if it comes after the resumed
+   // segment.
+   Strip_Ads bool
+   // when set, each media download written to a file is added, for Mux
+   Tracks *[]Track
+   Verify bool // compare the duration of each download with the manifest
+   Workers int // segments to fetch in parallel
    base *url.URL
+   duration time.Duration // of the presentation, zero if unknown
+   hls_attrs map[string]map[string]string // URI to master attributes
+   mpd map[string]mpd_representation // by ID
 }
 
 func (s *Stream) DASH(ref string) (dash.Representations, error) {
-   res, err := client.Redirect(nil).Get(ref)
+   return s.DASH_Context(context.Background(), ref)
+}
+
+func (s *Stream) DASH_Context(
+   ctx context.Context, ref string,
+) (dash.Representations, error) {
+   req, err := new_request(ctx, nil, ref)
+   if err != nil {
+      return nil, err
+   }
+   res, err := s.http_client().Redirect(nil).Do(req)
    if err != nil {
       return nil, err
    }
    defer res.Body.Close()
+   body, err := io.ReadAll(res.Body)
+   if err != nil {
+      return nil, err
+   }
    var pres dash.Presentation
-   if err := xml.NewDecoder(res.Body).Decode(&pres); err != nil {
+   if err := xml.Unmarshal(body, &pres); err != nil {
+      return nil, err
+   }
+   var extra mpd
+   if err := xml.Unmarshal(body, &extra); err != nil {
       return nil, err
    }
    s.base = res.Request.URL
+   s.duration, err = parse_duration(extra.Duration)
+   if err != nil {
+      return nil, err
+   }
+   s.mpd = extra.representations()
    return pres.Representation(), nil
 }
 
 func (s Stream) DASH_Get(items dash.Representations, index int) error {
+   return s.DASH_Get_Context(context.Background(), items, index)
+}
+
+// DASH_Get_Context stops when ctx is done. The file then ends on a whole
+// segment, and with Resume the download can go on from there.
+func (s Stream) DASH_Get_Context(
+   ctx context.Context, items dash.Representations, index int,
+) error {
    if s.Info {
+      if s.JSON != nil {
+         group := Info_Group{Index: index}
+         for _, item := range items {
+            group.Items = append(group.Items, s.dash_item(item))
+         }
+         s.JSON.Groups = append(s.JSON.Groups, group)
+         return nil
+      }
       for i, item := range items {
          if i == index {
             fmt.Print("!")
@@ -48,24 +181,93 @@ func (s Stream) DASH_Get(items dash.Representations, index int) error {
       return nil
    }
    item := items[index]
-   file, err := os.Clean("", s.Name + item.Ext()).Create()
+   init, media, err := s.dash_segments(ctx, item)
    if err != nil {
       return err
    }
-   defer file.Close()
-   req, err := http.NewRequest("GET", item.Initialization(), nil)
+   name := s.path(item.Ext())
+   file, check, err := s.create(name, item.ID)
    if err != nil {
       return err
    }
-   req.URL = s.base.ResolveReference(req.URL)
-   res, err := client.Redirect(nil).Do(req)
+   defer file.Close()
+   pro := progress.New_Segments(
+      file, s.Progress, name, len(media) - check.Segment - 1,
+   )
+   err = s.dash_get(ctx, item, init, media, file, check, pro)
+   if err := pro.Finish(err); err != nil {
+      return err
+   }
+   if err := s.verify(file, s.duration); err != nil {
+      return err
+   }
+   if err := file.Commit(); err != nil {
+      return err
+   }
+   s.add_track(name, item.Adaptation.Lang)
+   return check.remove()
+}
+
+// parse_duration reads an ISO 8601 duration such as PT1H2M3.5S. Years and
+// months are not used by DASH. Empty is zero.
+func parse_duration(text string) (time.Duration, error) {
+   if text == "" {
+      return 0, nil
+   }
+   if !strings.HasPrefix(text, "P") {
+      return 0, errors.New("duration " + text)
+   }
+   rest := text[1:]
+   var (
+      dur time.Duration
+      clock bool
+   )
+   for rest != "" {
+      if rest[0] == 'T' {
+         clock = true
+         rest = rest[1:]
+         continue
+      }
+      end := strings.IndexAny(rest, "DHMS")
+      if end <= 0 {
+         return 0, errors.New("duration " + text)
+      }
+      value, err := strconv.ParseFloat(rest[:end], 64)
+      if err != nil {
+         return 0, err
+      }
+      unit := time.Second
+      switch {
+      case rest[end] == 'D' && !clock:
+         unit = 24 * time.Hour
+      case rest[end] == 'H' && clock:
+         unit = time.Hour
+      case rest[end] == 'M' && clock:
+         unit = time.Minute
+      case rest[end] == 'S' && clock:
+      default:
+         return 0, errors.New("duration " + text)
+      }
+      dur += time.Duration(value * float64(unit))
+      rest = rest[end+1:]
+   }
+   return dur, nil
+}
+
+func (s Stream) dash_get(
+   ctx context.Context, item dash.Representation, init segment,
+   media []segment, file sink.Output, check *checkpoint,
+   pro *progress.Tracker,
+) error {
+   res, err := open_segment(ctx, s.http_client().Redirect(nil), s.base, init)
    if err != nil {
       return err
    }
    defer res.Body.Close()
-   media := item.Media()
-   pro := os.Progress_Chunks(file, len(media))
-   dec := mp4.New_Decrypt(pro)
+   limits := s.limiters()
+   body := rate.New_Reader(res.Body, limits...)
+   out := &skip_writer{Writer: pro, skip: check.resumed()}
+   dec := mp4.New_Decrypt(out)
    var key []byte
    if item.ContentProtection != nil {
       private_key, err := os.ReadFile(s.Private_Key)
@@ -84,42 +286,38 @@ func (s Stream) DASH_Get(items dash.Representations, index int) error {
       if err != nil {
          return err
       }
-      keys, err := mod.Post(s.Poster)
+      keys, err := mod.Post_Context(ctx, s.Poster)
       if err != nil {
          return err
       }
       key = keys.Content().Key
-      if err := dec.Init(res.Body); err != nil {
+      if err := dec.Init(body); err != nil {
          return err
       }
    } else {
-      _, err := io.Copy(pro, res.Body)
+      _, err := io.Copy(out, body)
       if err != nil {
          return err
       }
    }
-   for _, ref := range media {
-      req, err := http.NewRequest("GET", ref, nil)
-      if err != nil {
-         return err
-      }
-      req.URL = s.base.ResolveReference(req.URL)
-      res, err := client.Redirect(nil).Level(0).Do(req)
-      if err != nil {
-         return err
-      }
-      pro.Add_Chunk(res.ContentLength)
+   out.skip = false
+   if err := check.done(file, check.Segment); err != nil {
+      return err
+   }
+   write := func(i int, body []byte) error {
+      pro.Segment(i, int64(len(body)))
       if item.ContentProtection != nil {
-         err = dec.Segment(res.Body, key)
+         err := dec.Segment(bytes.NewReader(body), key)
+         if err != nil {
+            return err
+         }
       } else {
-         _, err = io.Copy(pro, res.Body)
-      }
-      if err != nil {
-         return err
-      }
-      if err := res.Body.Close(); err != nil {
-         return err
+         _, err := pro.Write(body)
+         if err != nil {
+            return err
+         }
       }
+      return check.done(file, i)
    }
-   return nil
+   return s.fetch(ctx, s.base, media, check.Segment + 1, write, limits...)
 }
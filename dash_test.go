@@ -0,0 +1,28 @@
+package mech
+
+import (
+   "testing"
+   "time"
+)
+
+var duration_tests = map[string]time.Duration{
+   "": 0,
+   "P1DT1S": 24 * time.Hour + time.Second,
+   "PT1H2M3.5S": time.Hour + 2 * time.Minute + 3500 * time.Millisecond,
+   "PT634.566S": 634566 * time.Millisecond,
+}
+
+func Test_Duration(t *testing.T) {
+   for text, want := range duration_tests {
+      got, err := parse_duration(text)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if got != want {
+         t.Fatal(text, got)
+      }
+   }
+   if _, err := parse_duration("PT1X"); err == nil {
+      t.Fatal("PT1X")
+   }
+}
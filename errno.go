@@ -0,0 +1,11 @@
+//go:build !plan9
+
+package mech
+
+import "syscall"
+
+var retry_errnos = []error{
+   syscall.ECONNREFUSED,
+   syscall.ECONNRESET,
+   syscall.EPIPE,
+}
@@ -0,0 +1,5 @@
+package mech
+
+// Plan 9 reports network errors as strings, so there are no numbers to
+// match
+var retry_errnos []error
@@ -0,0 +1,83 @@
+// Package extractor finds the site of an address. Site packages register
+// themselves, so a pasted link can go to the right one.
+package extractor
+
+import (
+   "errors"
+   "net/url"
+   "sort"
+   "strings"
+)
+
+// Extractor is a site, and how to get an ID from its addresses
+type Extractor struct {
+   Name string
+   Hosts []string // such as youtube.com, which also matches www.youtube.com
+   ID func(ref *url.URL) (string, error)
+}
+
+// matches reports whether host is one of Hosts, or under one
+func (e Extractor) matches(host string) bool {
+   host = strings.ToLower(host)
+   for _, h := range e.Hosts {
+      if host == h || strings.HasSuffix(host, "." + h) {
+         return true
+      }
+   }
+   return false
+}
+
+var extractors = make(map[string]Extractor)
+
+// Register panics if an extractor with the same name is registered twice
+func Register(e Extractor) {
+   if _, ok := extractors[e.Name]; ok {
+      panic("extractor: Register called twice for " + e.Name)
+   }
+   extractors[e.Name] = e
+}
+
+// Extractors returns those registered, by Name
+func Extractors() []Extractor {
+   var exts []Extractor
+   for _, e := range extractors {
+      exts = append(exts, e)
+   }
+   sort.Slice(exts, func(a, b int) bool {
+      return exts[a].Name < exts[b].Name
+   })
+   return exts
+}
+
+type not_found struct {
+   host string
+}
+
+func (n not_found) Error() string {
+   return "extractor: no site for " + n.host
+}
+
+// Find returns the extractor for an address, and the ID in it. An address
+// with no scheme is taken as https.
+func Find(address string) (*Extractor, string, error) {
+   if !strings.Contains(address, "://") {
+      address = "https://" + address
+   }
+   ref, err := url.Parse(address)
+   if err != nil {
+      return nil, "", err
+   }
+   if ref.Host == "" {
+      return nil, "", errors.New("extractor: no host in " + address)
+   }
+   for _, e := range Extractors() {
+      if e.matches(ref.Hostname()) {
+         id, err := e.ID(ref)
+         if err != nil {
+            return nil, "", err
+         }
+         return &e, id, nil
+      }
+   }
+   return nil, "", not_found{ref.Host}
+}
@@ -0,0 +1,27 @@
+package extractor
+
+import (
+   "net/url"
+   "path"
+   "testing"
+)
+
+func Test_Find(t *testing.T) {
+   Register(Extractor{
+      Name: "example",
+      Hosts: []string{"example.com"},
+      ID: func(ref *url.URL) (string, error) {
+         return path.Base(ref.Path), nil
+      },
+   })
+   ext, id, err := Find("www.example.com/watch/123")
+   if err != nil {
+      t.Fatal(err)
+   }
+   if ext.Name != "example" || id != "123" {
+      t.Fatal(ext.Name, id)
+   }
+   if _, _, err := Find("https://notexample.com/123"); err == nil {
+      t.Fatal("notexample.com")
+   }
+}
@@ -0,0 +1,179 @@
+package mech
+
+import (
+   "context"
+   "errors"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/verify"
+   "github.com/89z/rosso/http"
+   "io"
+   "net/url"
+   "strings"
+   "time"
+)
+
+type result struct {
+   body []byte
+   err error
+}
+
+// segment_error is a segment that could not be fetched, by its index in the
+// manifest
+type segment_error struct {
+   index int
+   err error
+}
+
+func (s segment_error) Error() string {
+   return s.err.Error()
+}
+
+func (s segment_error) Unwrap() error {
+   return s.err
+}
+
+// not_found reports whether err is the status of a 404, as the client
+// returns it
+func not_found(err error) bool {
+   return strings.HasPrefix(err.Error(), "404 ")
+}
+
+// new_request is a GET of ref, resolved against base if base is not nil
+func new_request(
+   ctx context.Context, base *url.URL, ref string,
+) (*http.Request, error) {
+   req, err := http.NewRequest("GET", ref, nil)
+   if err != nil {
+      return nil, err
+   }
+   if base != nil {
+      req.URL = base.ResolveReference(req.URL)
+   }
+   return req.WithContext(ctx), nil
+}
+
+// segment is a media segment, or part of one if byte_range is set
+type segment struct {
+   ref string
+   byte_range string // such as 100-199
+   duration time.Duration // EXTINF, zero for DASH
+   ad string // signal that marks an HLS ad break, empty for content
+}
+
+func whole(refs []string) []segment {
+   segs := make([]segment, len(refs))
+   for i, ref := range refs {
+      segs[i].ref = ref
+   }
+   return segs
+}
+
+// open_segment requests seg, with a Range header if it is part of a
+// resource
+func open_segment(
+   ctx context.Context, client http.Client, base *url.URL, seg segment,
+) (*http.Response, error) {
+   req, err := new_request(ctx, base, seg.ref)
+   if err != nil {
+      return nil, err
+   }
+   if seg.byte_range != "" {
+      req.Header.Set("Range", "bytes=" + seg.byte_range)
+      client = client.Status(206)
+   }
+   return client.Do(req)
+}
+
+// get_segment returns a verify.Length_Error if the body is not as long as
+// Content-Length. The body is read through limits.
+func get_segment(
+   ctx context.Context, client http.Client, base *url.URL, seg segment,
+   limits ...*rate.Limiter,
+) ([]byte, error) {
+   res, err := open_segment(ctx, client, base, seg)
+   if err != nil {
+      return nil, err
+   }
+   defer res.Body.Close()
+   body, err := io.ReadAll(rate.New_Reader(res.Body, limits...))
+   if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+      return nil, err
+   }
+   ref := res.Request.URL.String()
+   length := int64(len(body))
+   if err := verify.Length(ref, res.ContentLength, length); err != nil {
+      return nil, err
+   }
+   return body, err
+}
+
+// fetch downloads segs[start:] with up to Workers requests in flight, and
+// hands each body to write in manifest order. A body is held until it has
+// been written, so at most Workers segments are in memory at once. When ctx
+// is done no other segment is written, so the output ends on a whole
+// segment. A segment that is cut short is requested again, up to
+// Retry.Attempts. A segment that fails is returned as a segment_error. Each
+// worker reads through limits, so together they keep to the rate.
+func (s Stream) fetch(
+   ctx context.Context, base *url.URL, segs []segment, start int,
+   write func(int, []byte) error, limits ...*rate.Limiter,
+) error {
+   client := s.http_client().Redirect(nil).Level(0)
+   workers := s.Workers
+   if workers < 1 {
+      workers = 1
+   }
+   var (
+      done = make(chan struct{})
+      queue = make(chan chan result, workers)
+      slots = make(chan struct{}, workers)
+   )
+   defer close(done)
+   go func() {
+      defer close(queue)
+      for _, seg := range segs[start:] {
+         select {
+         case slots <- struct{}{}:
+         case <-done:
+            return
+         case <-ctx.Done():
+            return
+         }
+         out := make(chan result, 1)
+         queue <- out
+         go func(seg segment) {
+            var res result
+            for attempt := 1; ; attempt++ {
+               res.body, res.err = get_segment(
+                  ctx, client, base, seg, limits...,
+               )
+               var length verify.Length_Error
+               if !errors.As(res.err, &length) {
+                  break
+               }
+               if attempt >= s.Retry.Attempts || ctx.Err() != nil {
+                  break
+               }
+               s.retry().backoff(ctx, length.URL, attempt, res.err)
+            }
+            out <- res
+         }(seg)
+      }
+   }()
+   i := start
+   for out := range queue {
+      res := <-out
+      if res.err != nil {
+         return segment_error{i, res.err}
+      }
+      if err := ctx.Err(); err != nil {
+         return err
+      }
+      if err := write(i, res.body); err != nil {
+         return err
+      }
+      <-slots
+      i++
+   }
+   return ctx.Err()
+}
@@ -0,0 +1,129 @@
+package mech
+
+import (
+   "context"
+   "errors"
+   "github.com/89z/mech/verify"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "strconv"
+   "testing"
+   "time"
+)
+
+func Test_Fetch(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         i, err := strconv.Atoi(r.URL.Path[1:])
+         if err != nil {
+            t.Error(err)
+         }
+         // earlier segments finish last
+         time.Sleep(time.Duration(9 - i) * 9 * time.Millisecond)
+         w.Write([]byte(r.URL.Path))
+      },
+   ))
+   defer server.Close()
+   base, err := url.Parse(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   var refs []string
+   for i := 0; i < 9; i++ {
+      refs = append(refs, "/" + strconv.Itoa(i))
+   }
+   var str Stream
+   str.Workers = 4
+   var out []byte
+   write := func(i int, body []byte) error {
+      if string(body) != refs[i] {
+         t.Fatal(i, string(body))
+      }
+      out = append(out, body...)
+      return nil
+   }
+   err = str.fetch(context.Background(), base, whole(refs), 2, write)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if string(out) != "/2/3/4/5/6/7/8" {
+      t.Fatal(string(out))
+   }
+}
+
+func Test_Fetch_Cancel(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         w.Write([]byte(r.URL.Path))
+      },
+   ))
+   defer server.Close()
+   base, err := url.Parse(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   var refs []string
+   for i := 0; i < 9; i++ {
+      refs = append(refs, "/" + strconv.Itoa(i))
+   }
+   ctx, cancel := context.WithCancel(context.Background())
+   defer cancel()
+   var str Stream
+   str.Workers = 4
+   var out []byte
+   write := func(i int, body []byte) error {
+      out = append(out, body...)
+      if i == 1 {
+         cancel()
+      }
+      return nil
+   }
+   err = str.fetch(ctx, base, whole(refs), 0, write)
+   if !errors.Is(err, context.Canceled) {
+      t.Fatal(err)
+   }
+   if string(out) != "/0/1" {
+      t.Fatal(string(out))
+   }
+}
+
+func Test_Fetch_Length(t *testing.T) {
+   var requests int
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         requests++
+         w.Header().Set("Content-Length", "9")
+         if requests == 1 {
+            // promise more than is sent
+            w.Write([]byte("short"))
+            return
+         }
+         w.Write([]byte("123456789"))
+      },
+   ))
+   defer server.Close()
+   base, err := url.Parse(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   var str Stream
+   str.Progress = new(observer)
+   write := func(int, []byte) error {
+      return nil
+   }
+   err = str.fetch(context.Background(), base, whole([]string{"/0"}), 0, write)
+   var length verify.Length_Error
+   if !errors.As(err, &length) || length.Got != 5 {
+      t.Fatal(err)
+   }
+   // no retryable errors, so the segment is requested again whole
+   str.Retry = Retry{Attempts: 2, Delay: time.Millisecond, Errors: []error{}}
+   err = str.fetch(context.Background(), base, whole([]string{"/0"}), 0, write)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if requests != 2 {
+      t.Fatal(requests)
+   }
+}
@@ -1,107 +1,233 @@
 package mech
 
 import (
+   "bytes"
+   "context"
    "fmt"
+   "github.com/89z/mech/progress"
    "github.com/89z/rosso/hls"
-   "github.com/89z/rosso/os"
    "io"
-   "net/http"
+   "net/url"
+   "os"
 )
 
 func (s *Stream) HLS(ref string) (*hls.Master, error) {
-   res, err := client.Redirect(nil).Get(ref)
+   return s.HLS_Context(context.Background(), ref)
+}
+
+func (s *Stream) HLS_Context(
+   ctx context.Context, ref string,
+) (*hls.Master, error) {
+   req, err := new_request(ctx, nil, ref)
+   if err != nil {
+      return nil, err
+   }
+   res, err := s.http_client().Redirect(nil).Do(req)
    if err != nil {
       return nil, err
    }
    defer res.Body.Close()
+   body, err := io.ReadAll(res.Body)
+   if err != nil {
+      return nil, err
+   }
+   s.hls_attrs, err = master_attributes(bytes.NewReader(body))
+   if err != nil {
+      return nil, err
+   }
    s.base = res.Request.URL
-   return hls.New_Scanner(res.Body).Master()
+   return hls.New_Scanner(bytes.NewReader(body)).Master()
+}
+
+// HLS_Playlist downloads a media playlist that has no master, such as a
+// Twitter Space.
+func (s Stream) HLS_Playlist(ref string) error {
+   return s.HLS_Playlist_Context(context.Background(), ref)
+}
+
+func (s Stream) HLS_Playlist_Context(ctx context.Context, ref string) error {
+   var err error
+   s.base, err = url.Parse(ref)
+   if err != nil {
+      return err
+   }
+   return hls_get(ctx, s, hls.Media{{Raw_URI: ref}}, 0)
 }
 
 func (s Stream) HLS_Streams(items hls.Streams, index int) error {
-   return hls_get(s, items, index)
+   return hls_get(context.Background(), s, items, index)
+}
+
+// HLS_Streams_Context stops when ctx is done. The file then ends on a whole
+// segment, and with Resume the download can go on from there. A live
+// recording stops with what it has.
+func (s Stream) HLS_Streams_Context(
+   ctx context.Context, items hls.Streams, index int,
+) error {
+   return hls_get(ctx, s, items, index)
 }
 
 func (s Stream) HLS_Media(items hls.Media, index int) error {
-   return hls_get(s, items, index)
+   return hls_get(context.Background(), s, items, index)
+}
+
+func (s Stream) HLS_Media_Context(
+   ctx context.Context, items hls.Media, index int,
+) error {
+   return hls_get(ctx, s, items, index)
 }
 
-func hls_get[T hls.Mixed](str Stream, items []T, index int) error {
+func hls_get[T hls.Mixed](
+   ctx context.Context, str Stream, items []T, index int,
+) error {
    if str.Info {
+      var breaks []Info_Break
+      if str.Strip_Ads {
+         ref, err := str.base.Parse(items[index].URI())
+         if err != nil {
+            return err
+         }
+         play, _, err := str.get_playlist(ctx, ref)
+         if err != nil {
+            return err
+         }
+         breaks = play.strip()
+      }
+      if str.JSON != nil {
+         group := Info_Group{Index: index, Stripped: breaks}
+         for _, item := range items {
+            group.Items = append(group.Items, str.hls_item(item))
+         }
+         str.JSON.Groups = append(str.JSON.Groups, group)
+         return nil
+      }
       for i, item := range items {
          if i == index {
             fmt.Print("!")
          }
          fmt.Println(item)
       }
+      for _, brk := range breaks {
+         fmt.Println(brk)
+      }
       return nil
    }
    item := items[index]
-   file, err := os.Clean("", str.Name + item.Ext()).Create()
+   if str.Live {
+      ref, err := str.base.Parse(item.URI())
+      if err != nil {
+         return err
+      }
+      play, base, err := str.get_playlist(ctx, ref)
+      if err != nil {
+         return err
+      }
+      name := str.path(play.ext(item.Ext()))
+      file, err := str.sink().Create(name)
+      if err != nil {
+         return err
+      }
+      defer file.Close()
+      pro := progress.New_Segments(file, str.Progress, name, 0)
+      err = str.hls_live(ctx, pro, ref, play, base)
+      if err := pro.Finish(err); err != nil {
+         return err
+      }
+      return file.Commit()
+   }
+   req, err := new_request(ctx, str.base, item.URI())
    if err != nil {
       return err
    }
-   defer file.Close()
-   req, err := http.NewRequest("GET", item.URI(), nil)
+   res, err := str.http_client().Do(req)
    if err != nil {
       return err
    }
-   req.URL = str.base.ResolveReference(req.URL)
-   res, err := client.Do(req)
+   defer res.Body.Close()
+   play, err := new_playlist(res.Body)
    if err != nil {
       return err
    }
-   defer res.Body.Close()
-   seg, err := hls.New_Scanner(res.Body).Segment()
+   base := res.Request.URL
+   if str.Strip_Ads {
+      // stderr, as the media can be going to stdout
+      for _, brk := range play.strip() {
+         os.Stderr.WriteString(brk.String() + "\n")
+      }
+   }
+   name := str.path(play.ext(item.Ext()))
+   file, check, err := str.create(name, item.URI())
    if err != nil {
       return err
    }
+   defer file.Close()
    var block *hls.Block
-   if seg.Key != "" {
-      res, err := client.Get(seg.Key)
+   if play.key != "" {
+      key_ref, err := base.Parse(play.key)
       if err != nil {
          return err
       }
-      defer res.Body.Close()
-      key, err := io.ReadAll(res.Body)
+      block, err = str.get_key(ctx, key_ref)
       if err != nil {
          return err
       }
-      block, err = hls.New_Block(key)
+   }
+   pro := progress.New_Segments(
+      file, str.Progress, name, len(play.segments) - check.Segment - 1,
+   )
+   limits := str.limiters()
+   var init []byte
+   if play.init.ref != "" {
+      init, err = get_segment(
+         ctx, str.http_client(), base, play.init, limits...,
+      )
       if err != nil {
          return err
       }
    }
-   pro := os.Progress_Chunks(file, len(seg.URI))
-   for _, ref := range seg.URI {
-      req, err := http.NewRequest("GET", ref, nil)
-      if err != nil {
+   if init != nil && !check.resumed() {
+      if _, err := pro.Write(init); err != nil {
+         return err
+      }
+      if err := check.done(file, check.Segment); err != nil {
          return err
       }
-      req.URL = res.Request.URL.ResolveReference(req.URL)
-      res, err := client.Level(0).Redirect(nil).Do(req)
+   }
+   var retime *retimer
+   if str.Strip_Ads {
+      retime, err = new_retimer(init)
       if err != nil {
          return err
       }
-      pro.Add_Chunk(res.ContentLength)
+   }
+   write := func(i int, body []byte) error {
+      pro.Segment(i, int64(len(body)))
       if block != nil {
-         text, err := io.ReadAll(res.Body)
-         if err != nil {
-            return err
-         }
-         text = block.Decrypt_Key(text)
-         if _, err := pro.Write(text); err != nil {
-            return err
-         }
-      } else {
-         _, err := io.Copy(pro, res.Body)
+         body = block.Decrypt_Key(body)
+      }
+      if retime != nil {
+         var err error
+         body, err = retime.segment(body, play.segments[i].duration)
          if err != nil {
             return err
          }
       }
-      if err := res.Body.Close(); err != nil {
+      if _, err := pro.Write(body); err != nil {
          return err
       }
+      return check.done(file, i)
+   }
+   start := check.Segment + 1
+   err = str.fetch(ctx, base, play.segments, start, write, limits...)
+   if err := pro.Finish(err); err != nil {
+      return err
+   }
+   if err := str.verify(file, play.duration); err != nil {
+      return err
+   }
+   if err := file.Commit(); err != nil {
+      return err
    }
-   return nil
+   str.add_track(name, str.hls_attrs[item.URI()]["LANGUAGE"])
+   return check.remove()
 }
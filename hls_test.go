@@ -0,0 +1,132 @@
+package mech
+
+import (
+   "bytes"
+   "github.com/89z/mech/sink"
+   "github.com/89z/rosso/hls"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "os"
+   "strings"
+   "testing"
+   "time"
+)
+
+const fmp4_playlist = `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MAP:URI="main.mp4",BYTERANGE="4@0"
+#EXTINF:4,
+#EXT-X-BYTERANGE:3@4
+main.mp4
+#EXTINF:4,
+#EXT-X-BYTERANGE:3
+main.mp4
+#EXT-X-ENDLIST
+`
+
+func Test_Playlist(t *testing.T) {
+   play, err := new_playlist(strings.NewReader(fmp4_playlist))
+   if err != nil {
+      t.Fatal(err)
+   }
+   if play.init != (segment{ref: "main.mp4", byte_range: "0-3"}) {
+      t.Fatal(play.init)
+   }
+   want := []string{"4-6", "7-9"}
+   if len(play.segments) != 2 {
+      t.Fatal(play.segments)
+   }
+   for i, seg := range play.segments {
+      if seg.ref != "main.mp4" || seg.byte_range != want[i] {
+         t.Fatal(seg)
+      }
+   }
+   if ext := play.ext(".m4v"); ext != ".m4v" {
+      t.Fatal(ext)
+   }
+   play, err = new_playlist(strings.NewReader(media_playlist))
+   if err != nil {
+      t.Fatal(err)
+   }
+   if ext := play.ext(".m4v"); ext != ".ts" {
+      t.Fatal(ext)
+   }
+}
+
+const fmp4_body = "init0001112"
+
+func fmp4_server(t *testing.T) *httptest.Server {
+   return httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         switch r.URL.Path {
+         case "/index.m3u8":
+            w.Write([]byte(fmp4_playlist))
+         case "/main.mp4":
+            if r.Header.Get("Range") == "" {
+               t.Error("no Range")
+            }
+            http.ServeContent(
+               w, r, "", time.Time{}, strings.NewReader(fmp4_body),
+            )
+         }
+      },
+   ))
+}
+
+func Test_HLS_Map(t *testing.T) {
+   server := fmp4_server(t)
+   defer server.Close()
+   var str Stream
+   var err error
+   str.base, err = url.Parse(server.URL + "/master.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   chdir(t)
+   str.Name = "map"
+   str.Progress = new(observer)
+   err = str.HLS_Media(hls.Media{{Raw_URI: "index.m3u8"}}, 0)
+   if err != nil {
+      t.Fatal(err)
+   }
+   buf, err := os.ReadFile("map.m4a")
+   if err != nil {
+      t.Fatal(err)
+   }
+   if !bytes.Equal(buf, []byte(fmp4_body[:10])) {
+      t.Fatalf("%q", buf)
+   }
+}
+
+func Test_HLS_Sink(t *testing.T) {
+   server := fmp4_server(t)
+   defer server.Close()
+   var str Stream
+   var err error
+   str.base, err = url.Parse(server.URL + "/master.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   dir := chdir(t)
+   var buf bytes.Buffer
+   str.Name = "sink"
+   str.Progress = new(observer)
+   str.Resume = true
+   str.Sink = sink.New_Writer(&buf)
+   err = str.HLS_Media(hls.Media{{Raw_URI: "index.m3u8"}}, 0)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if buf.String() != fmp4_body[:10] {
+      t.Fatalf("%q", buf.String())
+   }
+   // not even a checkpoint
+   files, err := os.ReadDir(dir)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if len(files) >= 1 {
+      t.Fatal(files[0].Name())
+   }
+}
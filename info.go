@@ -0,0 +1,87 @@
+package mech
+
+import (
+   "encoding/json"
+   "github.com/89z/rosso/dash"
+   "github.com/89z/rosso/hls"
+   "io"
+   "strconv"
+)
+
+// Info_JSON is the info mode document. Each DASH_Get, DASH_Text or HLS call
+// adds a group, and Metadata is what the site returned for the item.
+type Info_JSON struct {
+   Metadata any `json:"metadata,omitempty"`
+   Groups []Info_Group `json:"groups"`
+}
+
+func (i Info_JSON) Write(w io.Writer) error {
+   body, err := json.MarshalIndent(i, "", " ")
+   if err != nil {
+      return err
+   }
+   _, err = w.Write(append(body, '\n'))
+   return err
+}
+
+type Info_Group struct {
+   Index int `json:"index"` // the chosen item
+   Items []Info_Item `json:"items"`
+   Stripped []Info_Break `json:"stripped,omitempty"` // with Strip_Ads
+}
+
+// Info_Item is a DASH representation, or an HLS variant or rendition
+type Info_Item struct {
+   Bandwidth int64 `json:"bandwidth,omitempty"`
+   Codecs string `json:"codecs,omitempty"`
+   Frame_Rate string `json:"frame_rate,omitempty"`
+   ID string `json:"id,omitempty"`
+   Lang string `json:"lang,omitempty"`
+   Mime_Type string `json:"mime_type,omitempty"`
+   Name string `json:"name,omitempty"`
+   Resolution string `json:"resolution,omitempty"`
+   Role string `json:"role,omitempty"`
+   Type string `json:"type,omitempty"`
+}
+
+func (s Stream) dash_item(r dash.Representation) Info_Item {
+   item := Info_Item{
+      Bandwidth: r.Bandwidth,
+      Codecs: r.Codecs,
+      Frame_Rate: s.mpd[r.ID].FrameRate,
+      ID: r.ID,
+      Mime_Type: r.MimeType,
+      Role: r.Role(),
+   }
+   if r.Adaptation != nil {
+      item.Lang = r.Adaptation.Lang
+   }
+   if r.Width >= 1 {
+      b := strconv.AppendInt(nil, r.Width, 10)
+      b = append(b, 'x')
+      item.Resolution = string(strconv.AppendInt(b, r.Height, 10))
+   }
+   return item
+}
+
+func (s Stream) hls_item(mixed hls.Mixed) Info_Item {
+   attr := s.hls_attrs[mixed.URI()]
+   switch v := mixed.(type) {
+   case hls.Stream:
+      return Info_Item{
+         Bandwidth: v.Bandwidth,
+         Codecs: v.Codecs,
+         Frame_Rate: attr["FRAME-RATE"],
+         Resolution: v.Resolution,
+         Type: "VIDEO",
+      }
+   case hls.Medium:
+      return Info_Item{
+         Lang: attr["LANGUAGE"],
+         Name: v.Name,
+         Role: v.Characteristics,
+         Type: v.Type,
+      }
+   }
+   return Info_Item{}
+}
@@ -0,0 +1,52 @@
+package mech
+
+import (
+   "bytes"
+   "encoding/json"
+   "net/http"
+   "net/http/httptest"
+   "testing"
+)
+
+const info_master = `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",LANGUAGE="en",URI="en.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=1000,CODECS="avc1.64001f,mp4a.40.2",RESOLUTION=1280x720,FRAME-RATE=29.970,AUDIO="aac"
+720.m3u8
+`
+
+func Test_Info(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         w.Write([]byte(info_master))
+      },
+   ))
+   defer server.Close()
+   str := Stream{Info: true, JSON: &Info_JSON{Metadata: "site"}}
+   master, err := str.HLS(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if err := str.HLS_Media(master.Media, 0); err != nil {
+      t.Fatal(err)
+   }
+   if err := str.HLS_Streams(master.Streams, 0); err != nil {
+      t.Fatal(err)
+   }
+   var buf bytes.Buffer
+   if err := str.JSON.Write(&buf); err != nil {
+      t.Fatal(err)
+   }
+   var info Info_JSON
+   if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+      t.Fatal(err)
+   }
+   if info.Metadata != "site" || len(info.Groups) != 2 {
+      t.Fatal(buf.String())
+   }
+   if info.Groups[0].Items[0].Lang != "en" {
+      t.Fatal(info.Groups[0])
+   }
+   if info.Groups[1].Items[0].Frame_Rate != "29.970" {
+      t.Fatal(info.Groups[1])
+   }
+}
@@ -0,0 +1,185 @@
+package mech
+
+import (
+   "context"
+   "errors"
+   "github.com/89z/mech/progress"
+   "github.com/89z/rosso/hls"
+   "io"
+   "net/url"
+   "os"
+   "strconv"
+   "time"
+)
+
+func (s Stream) get_playlist(
+   ctx context.Context, ref *url.URL,
+) (*playlist, *url.URL, error) {
+   req, err := new_request(ctx, nil, ref.String())
+   if err != nil {
+      return nil, nil, err
+   }
+   res, err := s.http_client().Level(0).Do(req)
+   if err != nil {
+      return nil, nil, err
+   }
+   defer res.Body.Close()
+   play, err := new_playlist(res.Body)
+   if err != nil {
+      return nil, nil, err
+   }
+   return play, res.Request.URL, nil
+}
+
+func (s Stream) get_key(ctx context.Context, ref *url.URL) (*hls.Block, error) {
+   req, err := new_request(ctx, nil, ref.String())
+   if err != nil {
+      return nil, err
+   }
+   res, err := s.http_client().Do(req)
+   if err != nil {
+      return nil, err
+   }
+   defer res.Body.Close()
+   key, err := io.ReadAll(res.Body)
+   if err != nil {
+      return nil, err
+   }
+   return hls.New_Block(key)
+}
+
+// hls_live records a live media playlist. The playlist is read again every
+// target duration, and only segments past the last media sequence written
+// are fetched. Recording stops at EXT-X-ENDLIST, after Live_Duration, or
+// when ctx is done, even in the middle of a fetch, and the file then ends on
+// a whole segment. A segment
+// that is gone before it is fetched is left as a gap. An EXT-X-MAP is
+// written before the first segment, and again if it changes. play and base
+// are the first read of ref, or nil to read it here.
+func (s Stream) hls_live(
+   ctx context.Context, pro *progress.Tracker, ref *url.URL,
+   play *playlist, base *url.URL,
+) error {
+   limits := s.limiters()
+   if s.Live_Duration >= 1 {
+      var cancel context.CancelFunc
+      ctx, cancel = context.WithTimeout(ctx, s.Live_Duration)
+      defer cancel()
+   }
+   var (
+      block *hls.Block
+      init segment
+      key string
+      next int64 = -1
+   )
+   for {
+      if play == nil {
+         var err error
+         play, base, err = s.get_playlist(ctx, ref)
+         if ctx.Err() != nil {
+            return nil
+         }
+         if err != nil {
+            return err
+         }
+      }
+      if play.key != key {
+         block = nil
+         if play.key != "" {
+            key_ref, err := base.Parse(play.key)
+            if err != nil {
+               return err
+            }
+            block, err = s.get_key(ctx, key_ref)
+            if ctx.Err() != nil {
+               return nil
+            }
+            if err != nil {
+               return err
+            }
+         }
+         key = play.key
+      }
+      if play.init.ref != "" && !same_segment(play.init, init) {
+         body, err := get_segment(
+            ctx, s.http_client(), base, play.init, limits...,
+         )
+         if ctx.Err() != nil {
+            return nil
+         }
+         if err != nil {
+            return err
+         }
+         if _, err := pro.Write(body); err != nil {
+            return err
+         }
+         init = play.init
+      }
+      start := 0
+      if next >= 0 {
+         start = int(next - play.sequence)
+         if start < 0 {
+            os.Stderr.WriteString("Live skipped ")
+            os.Stderr.WriteString(strconv.Itoa(-start))
+            os.Stderr.WriteString(" segments\n")
+            start = 0
+         }
+         if start > len(play.segments) {
+            start = len(play.segments)
+         }
+      }
+      write := func(i int, body []byte) error {
+         pro.Segment(int(play.sequence) + i, int64(len(body)))
+         if block != nil {
+            body = block.Decrypt_Key(body)
+         }
+         if _, err := pro.Write(body); err != nil {
+            return err
+         }
+         next = play.sequence + int64(i) + 1
+         return nil
+      }
+      for {
+         err := s.fetch(ctx, base, play.segments, start, write, limits...)
+         if ctx.Err() != nil {
+            return nil
+         }
+         if err == nil {
+            break
+         }
+         var gone segment_error
+         if !errors.As(err, &gone) || !not_found(gone.err) {
+            return err
+         }
+         // the segment left the window before it was fetched
+         os.Stderr.WriteString("Live gap at sequence ")
+         os.Stderr.WriteString(
+            strconv.FormatInt(play.sequence + int64(gone.index), 10),
+         )
+         os.Stderr.WriteString("\n")
+         next = play.sequence + int64(gone.index) + 1
+         start = gone.index + 1
+      }
+      os.Stderr.WriteString("Live sequence ")
+      os.Stderr.WriteString(strconv.FormatInt(next, 10))
+      os.Stderr.WriteString("\n")
+      if play.end_list {
+         return nil
+      }
+      wait := play.target_duration
+      if wait <= 0 {
+         wait = time.Second
+      }
+      select {
+      case <-time.After(wait):
+      case <-ctx.Done():
+         return nil
+      }
+      play = nil
+   }
+}
+
+// query strings can change with every playlist, so compare on the rest
+func same_segment(a, b segment) bool {
+   return same_address(a.ref, b.ref) && a.byte_range == b.byte_range
+}
@@ -0,0 +1,147 @@
+package mech
+
+import (
+   "bytes"
+   "context"
+   "fmt"
+   "github.com/89z/mech/progress"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "strconv"
+   "sync"
+   "testing"
+   "time"
+)
+
+func Test_Live(t *testing.T) {
+   buf, segments := live_record(t, "")
+   if buf != "/0/1/2/3/4" {
+      t.Fatal(buf)
+   }
+   if fmt.Sprint(segments) != "[0 1 2 3 4]" {
+      t.Fatal(segments)
+   }
+}
+
+// a segment that is gone leaves a gap, and the recording goes on
+func Test_Live_Gap(t *testing.T) {
+   buf, segments := live_record(t, "/1")
+   if buf != "/0/2/3/4" {
+      t.Fatal(buf)
+   }
+   if fmt.Sprint(segments) != "[0 2 3 4]" {
+      t.Fatal(segments)
+   }
+}
+
+// Live_Duration stops a recording during a fetch, not only between polls
+func Test_Live_Duration(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.URL.Path != "/live.m3u8" {
+            select {
+            case <-time.After(9 * time.Second):
+            case <-r.Context().Done():
+            }
+            return
+         }
+         w.Write([]byte("#EXTM3U\n#EXT-X-TARGETDURATION:1\n/0\n"))
+      },
+   ))
+   defer server.Close()
+   ref, err := url.Parse(server.URL + "/live.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var (
+      buf bytes.Buffer
+      events observer
+      str Stream
+   )
+   str.Live_Duration = 99 * time.Millisecond
+   pro := progress.New_Segments(&buf, &events, "live", 0)
+   begin := time.Now()
+   if err := str.hls_live(context.Background(), pro, ref, nil, nil); err != nil {
+      t.Fatal(err)
+   }
+   if elapsed := time.Since(begin); elapsed >= time.Second {
+      t.Fatal(elapsed)
+   }
+   if buf.Len() >= 1 {
+      t.Fatal(buf.String())
+   }
+}
+
+// live_record records a window moving over segments 0 to 4, where gone is
+// not found
+func live_record(t *testing.T, gone string) (string, []int) {
+   var poll int
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.URL.Path == gone {
+            http.NotFound(w, r)
+            return
+         }
+         if r.URL.Path != "/live.m3u8" {
+            w.Write([]byte(r.URL.Path))
+            return
+         }
+         // window of three segments, moving one segment per poll
+         b := []byte("#EXTM3U\n#EXT-X-TARGETDURATION:0.01\n")
+         b = append(b, "#EXT-X-MEDIA-SEQUENCE:"...)
+         b = strconv.AppendInt(b, int64(poll), 10)
+         b = append(b, '\n')
+         for i := poll; i < poll + 3; i++ {
+            b = append(b, '/')
+            b = strconv.AppendInt(b, int64(i), 10)
+            b = append(b, '\n')
+         }
+         if poll == 2 {
+            b = append(b, "#EXT-X-ENDLIST\n"...)
+         }
+         poll++
+         w.Write(b)
+      },
+   ))
+   defer server.Close()
+   ref, err := url.Parse(server.URL + "/live.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var (
+      buf bytes.Buffer
+      events observer
+      str Stream
+   )
+   pro := progress.New_Segments(&buf, &events, "live", 0)
+   err = str.hls_live(context.Background(), pro, ref, nil, nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   var segments []int
+   for _, event := range events.events {
+      if event.Kind == progress.Segment_Done {
+         segments = append(segments, event.Segment)
+      }
+   }
+   return buf.String(), segments
+}
+
+type observer struct {
+   events []progress.Event
+   mu sync.Mutex
+}
+
+func (o *observer) Observe(e progress.Event) {
+   o.mu.Lock()
+   o.events = append(o.events, e)
+   o.mu.Unlock()
+}
+
+func Test_Attributes(t *testing.T) {
+   attr := attributes(`#EXT-X-KEY:METHOD=AES-128,URI="a,b",IV=0x01`)
+   if attr["METHOD"] != "AES-128" || attr["URI"] != "a,b" || attr["IV"] != "0x01" {
+      t.Fatal(attr)
+   }
+}
@@ -0,0 +1,83 @@
+package meta
+
+import (
+   "bufio"
+   "errors"
+   "os"
+   "strings"
+   "sync"
+)
+
+// Archive is a file of the items already downloaded, with the site and ID
+// of one item on each line. A nil Archive has nothing, and adds nothing.
+type Archive struct {
+   file *os.File
+   items map[string]bool
+   mu sync.Mutex
+}
+
+func (d Data) archive_key() string {
+   return d.Site + " " + d.ID
+}
+
+// Open_Archive reads the archive name, and creates it if it does not exist.
+// An empty name returns a nil Archive.
+func Open_Archive(name string) (*Archive, error) {
+   if name == "" {
+      return nil, nil
+   }
+   file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0666)
+   if err != nil {
+      return nil, err
+   }
+   arc := Archive{file: file, items: make(map[string]bool)}
+   buf := bufio.NewScanner(file)
+   for buf.Scan() {
+      if line := strings.TrimSpace(buf.Text()); line != "" {
+         arc.items[line] = true
+      }
+   }
+   if err := buf.Err(); err != nil {
+      file.Close()
+      return nil, err
+   }
+   return &arc, nil
+}
+
+// Has reports if d was added before
+func (a *Archive) Has(d Data) bool {
+   if a == nil || d.ID == "" {
+      return false
+   }
+   a.mu.Lock()
+   defer a.mu.Unlock()
+   return a.items[d.archive_key()]
+}
+
+// Add records d, and should be called only after d has downloaded
+func (a *Archive) Add(d Data) error {
+   if a == nil {
+      return nil
+   }
+   if d.Site == "" || d.ID == "" {
+      return errors.New("archive needs a site and ID")
+   }
+   a.mu.Lock()
+   defer a.mu.Unlock()
+   key := d.archive_key()
+   if a.items[key] {
+      return nil
+   }
+   if _, err := a.file.WriteString(key + "\n"); err != nil {
+      return err
+   }
+   a.items[key] = true
+   return a.file.Sync()
+}
+
+func (a *Archive) Close() error {
+   if a == nil {
+      return nil
+   }
+   return a.file.Close()
+}
@@ -0,0 +1,51 @@
+package meta
+
+import (
+   "os"
+   "path/filepath"
+   "testing"
+)
+
+func Test_Archive(t *testing.T) {
+   name := filepath.Join(t.TempDir(), "archive.txt")
+   arc, err := Open_Archive(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   track := Data{ID: "1234", Site: "soundcloud"}
+   if arc.Has(track) {
+      t.Fatal(track)
+   }
+   if err := arc.Add(track); err != nil {
+      t.Fatal(err)
+   }
+   if err := arc.Add(track); err != nil {
+      t.Fatal(err)
+   }
+   if err := arc.Add(Data{Site: "nbc"}); err == nil {
+      t.Fatal("no ID")
+   }
+   arc.Close()
+   arc, err = Open_Archive(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer arc.Close()
+   if !arc.Has(track) {
+      t.Fatal(track)
+   }
+   if arc.Has(Data{ID: "1234", Site: "youtube"}) {
+      t.Fatal("youtube")
+   }
+   buf, err := os.ReadFile(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if string(buf) != "soundcloud 1234\n" {
+      t.Fatalf("%q", buf)
+   }
+   var none *Archive
+   if none.Has(track) || none.Add(track) != nil {
+      t.Fatal("nil")
+   }
+}
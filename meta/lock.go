@@ -0,0 +1,32 @@
+//go:build darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd
+
+package meta
+
+import (
+   "os"
+   "path/filepath"
+   "syscall"
+)
+
+// lock fails at once if another process holds file. The lock ends when
+// file is closed, or the process exits.
+func lock(file *os.File) error {
+   return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// rename while the lock is held, then sync the directory so the new name
+// is on disk too
+func rename(file *os.File, name string) error {
+   if err := os.Rename(file.Name(), name); err != nil {
+      return err
+   }
+   if err := file.Close(); err != nil {
+      return err
+   }
+   dir, err := os.Open(filepath.Dir(name))
+   if err != nil {
+      return err
+   }
+   defer dir.Close()
+   return dir.Sync()
+}
@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || windows)
+
+package meta
+
+import "os"
+
+// lock does nothing, as this platform has no advisory file locks. Two
+// processes writing the same name are then not stopped.
+func lock(file *os.File) error {
+   return nil
+}
+
+func rename(file *os.File, name string) error {
+   if err := file.Close(); err != nil {
+      return err
+   }
+   return os.Rename(file.Name(), name)
+}
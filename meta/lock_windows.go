@@ -0,0 +1,36 @@
+package meta
+
+import (
+   "os"
+   "syscall"
+   "unsafe"
+)
+
+var lock_file_ex = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+const (
+   lockfile_fail_immediately = 1
+   lockfile_exclusive_lock = 2
+)
+
+// lock fails at once if another process holds file. The lock ends when
+// file is closed, or the process exits.
+func lock(file *os.File) error {
+   var over syscall.Overlapped
+   ok, _, err := lock_file_ex.Call(
+      file.Fd(), lockfile_exclusive_lock|lockfile_fail_immediately, 0, 1, 0,
+      uintptr(unsafe.Pointer(&over)),
+   )
+   if ok == 0 {
+      return err
+   }
+   return nil
+}
+
+// an open file cannot be renamed, so this closes it first
+func rename(file *os.File, name string) error {
+   if err := file.Close(); err != nil {
+      return err
+   }
+   return os.Rename(file.Name(), name)
+}
@@ -0,0 +1,172 @@
+package meta
+
+import (
+   "errors"
+   "os"
+   "path/filepath"
+   "strconv"
+   "strings"
+)
+
+// Data is what every site knows of an item, such as a video or track. Empty
+// fields are unknown.
+type Data struct {
+   Author string
+   Date string // 2006-01-02
+   Episode string
+   ID string
+   Season string
+   Series string
+   Site string // the package, such as youtube
+   Title string
+}
+
+// field returns the value of a template field, and if it is known
+func (d Data) field(name string) (string, bool) {
+   switch name {
+   case "author":
+      return d.Author, true
+   case "date":
+      return d.Date, true
+   case "episode":
+      return d.Episode, true
+   case "id":
+      return d.ID, true
+   case "season":
+      return d.Season, true
+   case "series":
+      return d.Series, true
+   case "site":
+      return d.Site, true
+   case "title":
+      return d.Title, true
+   case "ext":
+      return "", true
+   }
+   return "", false
+}
+
+type part struct {
+   field string // empty for literal text
+   text string
+   width int // zero padding of numbers
+}
+
+// Template is an output file name, such as
+//   {series}/Season {season:02}/{series} - S{season:02}E{episode:02}.{ext}
+// Fields are author, date, episode, ext, id, season, series, site and title,
+// and :02 pads a number with zeros. Slashes in the template make
+// directories, but slashes and other characters that are not allowed in a
+// file name are removed from field values. The zero Template is unset.
+type Template struct {
+   parts []part
+   text string
+}
+
+func New_Template(text string) (*Template, error) {
+   var t Template
+   if err := t.Set(text); err != nil {
+      return nil, err
+   }
+   return &t, nil
+}
+
+// Set implements flag.Value
+func (t *Template) Set(text string) error {
+   t.parts = nil
+   t.text = text
+   for text != "" {
+      open := strings.IndexByte(text, '{')
+      if open == -1 {
+         open = len(text)
+      }
+      if strings.ContainsRune(text[:open], '}') {
+         return errors.New("template " + strconv.Quote(t.text))
+      }
+      if open >= 1 {
+         t.parts = append(t.parts, part{text: text[:open]})
+      }
+      if open == len(text) {
+         break
+      }
+      end := strings.IndexByte(text, '}')
+      if end == -1 {
+         return errors.New("template " + strconv.Quote(t.text))
+      }
+      var (
+         empty Data
+         p part
+      )
+      name, width, padded := strings.Cut(text[open+1:end], ":")
+      p.field = strings.ToLower(name)
+      if _, ok := empty.field(p.field); !ok {
+         return errors.New("template field " + strconv.Quote(name))
+      }
+      if padded {
+         var err error
+         p.width, err = strconv.Atoi(width)
+         if err != nil {
+            return err
+         }
+      }
+      t.parts = append(t.parts, p)
+      text = text[end+1:]
+   }
+   return nil
+}
+
+func (t Template) String() string {
+   return t.text
+}
+
+// Is_Set reports if the Template has been given
+func (t Template) Is_Set() bool {
+   return t.parts != nil
+}
+
+func clean(r rune) rune {
+   if strings.ContainsRune(`"*/:<>?\|`, r) {
+      return -1
+   }
+   return r
+}
+
+func pad(value string, width int) string {
+   if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+      return value
+   }
+   for len(value) < width {
+      value = "0" + value
+   }
+   return value
+}
+
+// Execute returns the file name of d, with ext as the ext field. ext can
+// start with a dot.
+func (t Template) Execute(d Data, ext string) string {
+   var buf strings.Builder
+   for _, p := range t.parts {
+      if p.field == "" {
+         buf.WriteString(p.text)
+         continue
+      }
+      value, _ := d.field(p.field)
+      if p.field == "ext" {
+         value = strings.TrimPrefix(ext, ".")
+      }
+      buf.WriteString(strings.Map(clean, pad(value, p.width)))
+   }
+   return filepath.FromSlash(buf.String())
+}
+
+// Create makes the directories of name, and then the file
+func Create(name string) (*os.File, error) {
+   if dir := filepath.Dir(name); dir != "." {
+      err := os.MkdirAll(dir, os.ModePerm)
+      if err != nil {
+         return nil, err
+      }
+   }
+   os.Stderr.WriteString("Create " + name + "\n")
+   return os.Create(name)
+}
@@ -0,0 +1,67 @@
+package meta
+
+import (
+   "os"
+   "path/filepath"
+   "testing"
+)
+
+var episode = Data{
+   Episode: "3",
+   Season: "1",
+   Series: "Mr. Robot",
+   Title: "eps1.2_d3bug.mkv",
+}
+
+var template_tests = []struct {
+   text string
+   want string
+}{
+   {
+      "{series}/Season {season:02}/{series} - S{season:02}E{episode:02} - {title}.{ext}",
+      "Mr. Robot/Season 01/Mr. Robot - S01E03 - eps1.2_d3bug.mkv.mp4",
+   },
+   {"{author}-{title}.{ext}", "-eps1.2_d3bug.mkv.mp4"},
+   {"{season:03}", "001"},
+}
+
+func Test_Template(t *testing.T) {
+   for _, test := range template_tests {
+      temp, err := New_Template(test.text)
+      if err != nil {
+         t.Fatal(err)
+      }
+      name := temp.Execute(episode, ".mp4")
+      if name != filepath.FromSlash(test.want) {
+         t.Fatal(name)
+      }
+   }
+   for _, text := range []string{"{series", "series}", "{year}", "{season:x}"} {
+      if _, err := New_Template(text); err == nil {
+         t.Fatal(text)
+      }
+   }
+}
+
+func Test_Clean(t *testing.T) {
+   temp, err := New_Template("{series}/{title}")
+   if err != nil {
+      t.Fatal(err)
+   }
+   name := temp.Execute(Data{Series: "AC/DC", Title: "What?"}, "")
+   if name != filepath.FromSlash("ACDC/What") {
+      t.Fatal(name)
+   }
+}
+
+func Test_Create(t *testing.T) {
+   name := filepath.Join(t.TempDir(), "a", "b", "c.txt")
+   file, err := Create(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   file.Close()
+   if _, err := os.Stat(name); err != nil {
+      t.Fatal(err)
+   }
+}
@@ -0,0 +1,63 @@
+package meta
+
+import (
+   "os"
+   "path/filepath"
+)
+
+// Part is a download in progress. It is written to its name with ".part"
+// added, which is locked so that no other process writes it too. Only
+// Commit gives it the final name, so a crash never leaves a file that looks
+// finished.
+type Part struct {
+   *os.File
+   name string
+}
+
+// Create_Part makes the directories of name, and then an empty name.part
+func Create_Part(name string) (*Part, error) {
+   if dir := filepath.Dir(name); dir != "." {
+      err := os.MkdirAll(dir, os.ModePerm)
+      if err != nil {
+         return nil, err
+      }
+   }
+   os.Stderr.WriteString("Create " + name + "\n")
+   part, err := open_part(name, os.O_CREATE)
+   if err != nil {
+      return nil, err
+   }
+   if err := part.Truncate(0); err != nil {
+      part.Close()
+      return nil, err
+   }
+   return part, nil
+}
+
+// Open_Part opens name.part as it was left, to resume it
+func Open_Part(name string) (*Part, error) {
+   return open_part(name, 0)
+}
+
+// the lock comes before any change, so a file that another process holds
+// is not truncated
+func open_part(name string, flag int) (*Part, error) {
+   file, err := os.OpenFile(name + ".part", flag|os.O_RDWR, 0666)
+   if err != nil {
+      return nil, err
+   }
+   if err := lock(file); err != nil {
+      file.Close()
+      return nil, &os.PathError{Op: "lock", Path: file.Name(), Err: err}
+   }
+   return &Part{File: file, name: name}, nil
+}
+
+// Commit syncs the file, and then renames it to the final name and closes
+// it. A Part that is closed without Commit keeps the ".part" name.
+func (p *Part) Commit() error {
+   if err := p.Sync(); err != nil {
+      return err
+   }
+   return rename(p.File, p.name)
+}
@@ -0,0 +1,37 @@
+package meta
+
+import (
+   "os"
+   "testing"
+)
+
+func Test_Part(t *testing.T) {
+   name := t.TempDir() + "/dir/part.mp4"
+   part, err := Create_Part(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer part.Close()
+   if _, err := Create_Part(name); err == nil {
+      t.Fatal("second writer")
+   }
+   if _, err := part.WriteString("part"); err != nil {
+      t.Fatal(err)
+   }
+   if _, err := os.Stat(name); !os.IsNotExist(err) {
+      t.Fatal(err)
+   }
+   if err := part.Commit(); err != nil {
+      t.Fatal(err)
+   }
+   buf, err := os.ReadFile(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if string(buf) != "part" {
+      t.Fatal(string(buf))
+   }
+   if _, err := os.Stat(name + ".part"); !os.IsNotExist(err) {
+      t.Fatal(err)
+   }
+}
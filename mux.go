@@ -0,0 +1,498 @@
+package mech
+
+import (
+   "bufio"
+   "errors"
+   "github.com/89z/mech/meta"
+   "github.com/89z/mech/sink"
+   "github.com/edgeware/mp4ff/mp4"
+   "io"
+   "os"
+   "strings"
+)
+
+// every ISO 639-1 code, to the ISO 639-2/T code that the mdhd box holds
+var languages = map[string]string{
+   "aa": "aar",
+   "ab": "abk",
+   "ae": "ave",
+   "af": "afr",
+   "ak": "aka",
+   "am": "amh",
+   "an": "arg",
+   "ar": "ara",
+   "as": "asm",
+   "av": "ava",
+   "ay": "aym",
+   "az": "aze",
+   "ba": "bak",
+   "be": "bel",
+   "bg": "bul",
+   "bh": "bih",
+   "bi": "bis",
+   "bm": "bam",
+   "bn": "ben",
+   "bo": "bod",
+   "br": "bre",
+   "bs": "bos",
+   "ca": "cat",
+   "ce": "che",
+   "ch": "cha",
+   "co": "cos",
+   "cr": "cre",
+   "cs": "ces",
+   "cu": "chu",
+   "cv": "chv",
+   "cy": "cym",
+   "da": "dan",
+   "de": "deu",
+   "dv": "div",
+   "dz": "dzo",
+   "ee": "ewe",
+   "el": "ell",
+   "en": "eng",
+   "eo": "epo",
+   "es": "spa",
+   "et": "est",
+   "eu": "eus",
+   "fa": "fas",
+   "ff": "ful",
+   "fi": "fin",
+   "fj": "fij",
+   "fo": "fao",
+   "fr": "fra",
+   "fy": "fry",
+   "ga": "gle",
+   "gd": "gla",
+   "gl": "glg",
+   "gn": "grn",
+   "gu": "guj",
+   "gv": "glv",
+   "ha": "hau",
+   "he": "heb",
+   "hi": "hin",
+   "ho": "hmo",
+   "hr": "hrv",
+   "ht": "hat",
+   "hu": "hun",
+   "hy": "hye",
+   "hz": "her",
+   "ia": "ina",
+   "id": "ind",
+   "ie": "ile",
+   "ig": "ibo",
+   "ii": "iii",
+   "ik": "ipk",
+   "io": "ido",
+   "is": "isl",
+   "it": "ita",
+   "iu": "iku",
+   "ja": "jpn",
+   "jv": "jav",
+   "ka": "kat",
+   "kg": "kon",
+   "ki": "kik",
+   "kj": "kua",
+   "kk": "kaz",
+   "kl": "kal",
+   "km": "khm",
+   "kn": "kan",
+   "ko": "kor",
+   "kr": "kau",
+   "ks": "kas",
+   "ku": "kur",
+   "kv": "kom",
+   "kw": "cor",
+   "ky": "kir",
+   "la": "lat",
+   "lb": "ltz",
+   "lg": "lug",
+   "li": "lim",
+   "ln": "lin",
+   "lo": "lao",
+   "lt": "lit",
+   "lu": "lub",
+   "lv": "lav",
+   "mg": "mlg",
+   "mh": "mah",
+   "mi": "mri",
+   "mk": "mkd",
+   "ml": "mal",
+   "mn": "mon",
+   "mr": "mar",
+   "ms": "msa",
+   "mt": "mlt",
+   "my": "mya",
+   "na": "nau",
+   "nb": "nob",
+   "nd": "nde",
+   "ne": "nep",
+   "ng": "ndo",
+   "nl": "nld",
+   "nn": "nno",
+   "no": "nor",
+   "nr": "nbl",
+   "nv": "nav",
+   "ny": "nya",
+   "oc": "oci",
+   "oj": "oji",
+   "om": "orm",
+   "or": "ori",
+   "os": "oss",
+   "pa": "pan",
+   "pi": "pli",
+   "pl": "pol",
+   "ps": "pus",
+   "pt": "por",
+   "qu": "que",
+   "rm": "roh",
+   "rn": "run",
+   "ro": "ron",
+   "ru": "rus",
+   "rw": "kin",
+   "sa": "san",
+   "sc": "srd",
+   "sd": "snd",
+   "se": "sme",
+   "sg": "sag",
+   "si": "sin",
+   "sk": "slk",
+   "sl": "slv",
+   "sm": "smo",
+   "sn": "sna",
+   "so": "som",
+   "sq": "sqi",
+   "sr": "srp",
+   "ss": "ssw",
+   "st": "sot",
+   "su": "sun",
+   "sv": "swe",
+   "sw": "swa",
+   "ta": "tam",
+   "te": "tel",
+   "tg": "tgk",
+   "th": "tha",
+   "ti": "tir",
+   "tk": "tuk",
+   "tl": "tgl",
+   "tn": "tsn",
+   "to": "ton",
+   "tr": "tur",
+   "ts": "tso",
+   "tt": "tat",
+   "tw": "twi",
+   "ty": "tah",
+   "ug": "uig",
+   "uk": "ukr",
+   "ur": "urd",
+   "uz": "uzb",
+   "ve": "ven",
+   "vi": "vie",
+   "vo": "vol",
+   "wa": "wln",
+   "wo": "wol",
+   "xh": "xho",
+   "yi": "yid",
+   "yo": "yor",
+   "za": "zha",
+   "zh": "zho",
+   "zu": "zul",
+}
+
+// Track is an input to Mux. Lang is a BCP 47 tag such as "en" or "es-MX".
+type Track struct {
+   Name string
+   Lang string
+}
+
+type mux_input struct {
+   ids map[uint32]uint32 // input track ID to output track ID
+   mdat mp4.Box
+   moof *mp4.MoofBox
+   pos uint64
+   read *bufio.Reader
+   scale map[uint32]uint32
+   time float64 // decode time of moof, in seconds
+}
+
+func (m *mux_input) box() (mp4.Box, error) {
+   box, err := mp4.DecodeBox(m.pos, m.read)
+   if err != nil {
+      return nil, err
+   }
+   m.pos += box.Size()
+   return box, nil
+}
+
+// next reads the next fragment, or returns io.EOF
+func (m *mux_input) next() error {
+   m.moof, m.mdat = nil, nil
+   for m.mdat == nil {
+      box, err := m.box()
+      if err != nil {
+         return err
+      }
+      switch box := box.(type) {
+      case *mp4.MoofBox:
+         m.moof = box
+      case *mp4.MdatBox:
+         if m.moof == nil {
+            return errors.New("mdat before moof")
+         }
+         m.mdat = box
+      }
+   }
+   for _, traf := range m.moof.Trafs {
+      if traf.Tfhd.HasBaseDataOffset() {
+         return errors.New("tfhd base data offset")
+      }
+   }
+   traf := m.moof.Traf
+   if traf.Tfdt != nil {
+      scale := m.scale[traf.Tfhd.TrackID]
+      if scale >= 1 {
+         m.time = float64(traf.Tfdt.BaseMediaDecodeTime) / float64(scale)
+      }
+   }
+   return nil
+}
+
+// moov skips to the movie box, which must come before the fragments
+func (m *mux_input) moov() (*mp4.MoovBox, error) {
+   for {
+      box, err := m.box()
+      if err != nil {
+         if err == io.EOF {
+            return nil, errors.New("moov not found")
+         }
+         return nil, err
+      }
+      switch box := box.(type) {
+      case *mp4.MoovBox:
+         if box.Mvex == nil {
+            return nil, errors.New("not fragmented MP4")
+         }
+         return box, nil
+      case *mp4.MoofBox, *mp4.MdatBox:
+         return nil, errors.New("moov not found")
+      }
+   }
+}
+
+func language(tag string) string {
+   primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+   if len(primary) == 3 {
+      return primary
+   }
+   if lang, ok := languages[primary]; ok {
+      return lang
+   }
+   return "und"
+}
+
+// set_language writes the mdhd language, and the elng box for the full tag
+func set_language(trak *mp4.TrakBox, tag string) {
+   if tag == "" {
+      return
+   }
+   mdia := trak.Mdia
+   mdia.Mdhd.SetLanguage(language(tag))
+   if mdia.Elng != nil {
+      mdia.Elng.Language = tag
+      return
+   }
+   mdia.Elng = mp4.CreateElng(tag)
+   var children []mp4.Box
+   for _, child := range mdia.Children {
+      if child.Type() == "minf" {
+         children = append(children, mdia.Elng)
+      }
+      children = append(children, child)
+   }
+   mdia.Children = children
+}
+
+// add_trak puts a trak after the others
+func add_trak(moov *mp4.MoovBox, trak *mp4.TrakBox) {
+   last := len(moov.Children) - 1
+   for i, child := range moov.Children {
+      if child.Type() == "trak" {
+         last = i
+      }
+   }
+   var children []mp4.Box
+   children = append(children, moov.Children[:last+1]...)
+   children = append(children, trak)
+   children = append(children, moov.Children[last+1:]...)
+   moov.Children = children
+   moov.Traks = append(moov.Traks, trak)
+}
+
+// Mux combines fragmented MP4 files into one fragmented MP4. The first
+// track is usually the video, and its movie box is kept. Each track after
+// that gets new track IDs, and all audio tracks are put in one alternate
+// group, so that players offer them as a choice. Fragments are written in
+// decode time order.
+func Mux(dst io.Writer, tracks ...Track) error {
+   var (
+      inputs []*mux_input
+      out *mp4.MoovBox
+   )
+   for i, track := range tracks {
+      file, err := os.Open(track.Name)
+      if err != nil {
+         return err
+      }
+      defer file.Close()
+      in := &mux_input{
+         ids: make(map[uint32]uint32),
+         read: bufio.NewReader(file),
+         scale: make(map[uint32]uint32),
+      }
+      moov, err := in.moov()
+      if err != nil {
+         return errors.New(track.Name + ": " + err.Error())
+      }
+      if i == 0 {
+         out = moov
+      }
+      for _, trak := range moov.Traks {
+         old := trak.Tkhd.TrackID
+         id := old
+         in.scale[old] = trak.Mdia.Mdhd.Timescale
+         if i >= 1 {
+            id = out.Mvhd.NextTrackID
+            out.Mvhd.NextTrackID++
+            for _, trex := range moov.Mvex.Trexs {
+               if trex.TrackID == old {
+                  clone := *trex
+                  clone.TrackID = id
+                  out.Mvex.AddChild(&clone)
+               }
+            }
+            trak.Tkhd.TrackID = id
+            add_trak(out, trak)
+         } else if id >= out.Mvhd.NextTrackID {
+            out.Mvhd.NextTrackID = id + 1
+         }
+         in.ids[old] = id
+         if trak.Mdia.Hdlr.HandlerType == "soun" {
+            trak.Tkhd.AlternateGroup = 1
+         }
+         set_language(trak, track.Lang)
+      }
+      inputs = append(inputs, in)
+   }
+   if out == nil {
+      return errors.New("no tracks")
+   }
+   ftyp := mp4.NewFtyp("iso6", 0, []string{"iso6", "dash", "mp41"})
+   if err := ftyp.Encode(dst); err != nil {
+      return err
+   }
+   if err := out.Encode(dst); err != nil {
+      return err
+   }
+   var live []*mux_input
+   for _, in := range inputs {
+      err := in.next()
+      if err == io.EOF {
+         continue
+      }
+      if err != nil {
+         return err
+      }
+      live = append(live, in)
+   }
+   var sequence uint32
+   for len(live) >= 1 {
+      first := 0
+      for i, in := range live {
+         if in.time < live[first].time {
+            first = i
+         }
+      }
+      in := live[first]
+      for _, traf := range in.moof.Trafs {
+         id, ok := in.ids[traf.Tfhd.TrackID]
+         if !ok {
+            return errors.New("traf for unknown track")
+         }
+         traf.Tfhd.TrackID = id
+      }
+      sequence++
+      in.moof.Mfhd.SequenceNumber = sequence
+      if err := in.moof.Encode(dst); err != nil {
+         return err
+      }
+      if err := in.mdat.Encode(dst); err != nil {
+         return err
+      }
+      err := in.next()
+      if err == io.EOF {
+         live = append(live[:first], live[first+1:]...)
+      } else if err != nil {
+         return err
+      }
+   }
+   return nil
+}
+
+// Mux combines tracks into one file, Name or Output with the ".mp4"
+// extension. Tracks are numbered in order, and are usually one video and
+// one or more audio. With remove, the inputs are removed after.
+func (s Stream) Mux(tracks []Track, remove bool) error {
+   for _, track := range tracks {
+      ts, err := mpeg_ts(track.Name)
+      if err != nil {
+         return err
+      }
+      if ts {
+         return errors.New("Mux reads MP4, not MPEG-TS " + track.Name)
+      }
+   }
+   file, err := meta.Create_Part(s.path(".mp4"))
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   if err := Mux(file, tracks...); err != nil {
+      return err
+   }
+   if err := file.Commit(); err != nil {
+      return err
+   }
+   if remove {
+      for _, track := range tracks {
+         if err := os.Remove(track.Name); err != nil {
+            return err
+         }
+      }
+   }
+   return nil
+}
+
+// mpeg_ts reports whether name starts with the sync byte of a transport
+// stream, as an MP4 box size cannot, whatever the extension
+func mpeg_ts(name string) (bool, error) {
+   file, err := os.Open(name)
+   if err != nil {
+      return false, err
+   }
+   defer file.Close()
+   head := make([]byte, 1)
+   if _, err := file.Read(head); err != nil {
+      if err == io.EOF {
+         return false, nil
+      }
+      return false, err
+   }
+   return head[0] == 'G', nil
+}
+
+// add_track adds an output to Tracks, if it is set and the output is a file
+func (s Stream) add_track(name, lang string) {
+   if _, ok := s.sink().(sink.File); ok && s.Tracks != nil {
+      *s.Tracks = append(*s.Tracks, Track{Name: name, Lang: lang})
+   }
+}
@@ -0,0 +1,149 @@
+package mech
+
+import (
+   "github.com/edgeware/mp4ff/mp4"
+   "os"
+   "testing"
+)
+
+// fragmented MP4 with one track, and one sample per fragment
+func create_track(name, media string, scale uint32, times []uint64) error {
+   file, err := os.Create(name)
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   init := mp4.CreateEmptyInit()
+   init.AddEmptyTrack(scale, media, "und")
+   if err := init.Encode(file); err != nil {
+      return err
+   }
+   for i, time := range times {
+      frag, err := mp4.CreateFragment(uint32(i + 1), 1)
+      if err != nil {
+         return err
+      }
+      frag.AddFullSample(mp4.FullSample{
+         Sample: mp4.Sample{Dur: uint32(scale), Size: 1},
+         DecodeTime: time,
+         Data: []byte{byte(i)},
+      })
+      if err := frag.Encode(file); err != nil {
+         return err
+      }
+   }
+   return nil
+}
+
+func Test_Mux(t *testing.T) {
+   dir := t.TempDir()
+   video, audio := dir + "/mux.m4v", dir + "/mux.m4a"
+   if err := create_track(video, "video", 90000, []uint64{0, 90000}); err != nil {
+      t.Fatal(err)
+   }
+   if err := create_track(audio, "audio", 48000, []uint64{0, 48000}); err != nil {
+      t.Fatal(err)
+   }
+   out, err := os.Create(dir + "/mux.mp4")
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer out.Close()
+   err = Mux(out, Track{Name: video}, Track{Name: audio, Lang: "es-MX"})
+   if err != nil {
+      t.Fatal(err)
+   }
+   if _, err := out.Seek(0, 0); err != nil {
+      t.Fatal(err)
+   }
+   file, err := mp4.DecodeFile(out)
+   if err != nil {
+      t.Fatal(err)
+   }
+   traks := file.Init.Moov.Traks
+   if len(traks) != 2 || traks[1].Tkhd.TrackID != 2 {
+      t.Fatal(traks)
+   }
+   if traks[1].Mdia.Mdhd.GetLanguage() != "spa" {
+      t.Fatal(traks[1].Mdia.Mdhd.GetLanguage())
+   }
+   if traks[1].Mdia.Elng.Language != "es-MX" {
+      t.Fatal(traks[1].Mdia.Elng)
+   }
+   if len(file.Init.Moov.Mvex.Trexs) != 2 {
+      t.Fatal(file.Init.Moov.Mvex.Trexs)
+   }
+   var ids []uint32
+   for _, seg := range file.Segments {
+      for _, frag := range seg.Fragments {
+         ids = append(ids, frag.Moof.Traf.Tfhd.TrackID)
+      }
+   }
+   if len(ids) != 4 || ids[1] == ids[2] {
+      t.Fatal(ids)
+   }
+}
+
+func Test_Stream_Mux(t *testing.T) {
+   chdir(t)
+   tracks := []Track{
+      {Name: "mux.m4v"},
+      {Name: "mux-en.m4a", Lang: "en"},
+      {Name: "mux-es.m4a", Lang: "es"},
+   }
+   if err := create_track(tracks[0].Name, "video", 90000, []uint64{0}); err != nil {
+      t.Fatal(err)
+   }
+   for _, track := range tracks[1:] {
+      if err := create_track(track.Name, "audio", 48000, []uint64{0}); err != nil {
+         t.Fatal(err)
+      }
+   }
+   str := Stream{Name: "mux"}
+   if err := str.Mux(tracks, false); err != nil {
+      t.Fatal(err)
+   }
+   file, err := os.Open("mux.mp4")
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer file.Close()
+   dec, err := mp4.DecodeFile(file)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if len(dec.Init.Moov.Traks) != 3 {
+      t.Fatal(dec.Init.Moov.Traks)
+   }
+   // the inputs are kept
+   for _, track := range tracks {
+      if _, err := os.Stat(track.Name); err != nil {
+         t.Fatal(err)
+      }
+   }
+   // a transport stream is refused by its content, not its extension
+   ts := make([]byte, 188)
+   ts[0] = 'G'
+   if err := os.WriteFile("mux-ts.m4v", ts, os.ModePerm); err != nil {
+      t.Fatal(err)
+   }
+   if str.Mux([]Track{{Name: "mux-ts.m4v"}}, false) == nil {
+      t.Fatal("MPEG-TS")
+   }
+}
+
+var language_tests = map[string]string{
+   "en": "eng",
+   "es-MX": "spa",
+   "fil": "fil",
+   "sw": "swa",
+   "x": "und",
+}
+
+func Test_Language(t *testing.T) {
+   for tag, want := range language_tests {
+      if lang := language(tag); lang != want {
+         t.Fatal(tag, lang)
+      }
+   }
+}
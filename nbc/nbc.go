@@ -2,20 +2,50 @@ package nbc
 
 import (
    "bytes"
+   "context"
    "crypto/hmac"
    "crypto/sha256"
    "encoding/hex"
    "encoding/json"
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/meta"
    "github.com/89z/rosso/http"
    "io"
+   "net/url"
+   "path"
    "strconv"
    "strings"
    "time"
 )
 
+// nbc.com/la-brea/video/pilot/9000194212
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "nbc",
+      Hosts: []string{"nbc.com"},
+      ID: func(ref *url.URL) (string, error) {
+         guid := path.Base(ref.Path)
+         if _, err := strconv.ParseInt(guid, 10, 64); err != nil {
+            return "", err
+         }
+         return guid, nil
+      },
+   })
+}
+
 const persisted_query = "6ea2e204ad35f81db0e2fdfd5a32844ceff1bdd38e0e7d2b15c5e46b7df1b0cc"
 
+// Base_URL has the NBC endpoints for media access and GraphQL
+type Base_URL struct {
+   Access string
+   GraphQL string
+}
+
 var (
+   Base = Base_URL{
+      Access: "http://access-cloudpath.media.nbcuni.com",
+      GraphQL: "https://friendship.nbc.co/v2/graphql",
+   }
    Client = http.Default_Client
    secret_key = []byte("2b84a073ede61c766e4c0b3f1e656f7f")
 )
@@ -41,9 +71,24 @@ type Bonanza_Page struct {
       MpxAccountId string
    }
    Name string
+   guid int64
+}
+
+func (b Bonanza_Page) Meta() meta.Data {
+   return meta.Data{
+      ID: strconv.FormatInt(b.guid, 10),
+      Site: "nbc",
+      Title: b.Analytics.ConvivaAssetName,
+   }
 }
 
 func New_Bonanza_Page(guid int64) (*Bonanza_Page, error) {
+   return New_Bonanza_Page_Context(context.Background(), guid)
+}
+
+func New_Bonanza_Page_Context(
+   ctx context.Context, guid int64,
+) (*Bonanza_Page, error) {
    var p page_request
    p.Extensions.Persisted_Query.SHA_256_Hash = persisted_query
    p.Variables.App = "nbc"
@@ -56,13 +101,13 @@ func New_Bonanza_Page(guid int64) (*Bonanza_Page, error) {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", "https://friendship.nbc.co/v2/graphql", bytes.NewReader(body),
+      "POST", Base.GraphQL, bytes.NewReader(body),
    )
    if err != nil {
       return nil, err
    }
    req.Header.Set("Content-Type", "application/json")
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -75,10 +120,15 @@ func New_Bonanza_Page(guid int64) (*Bonanza_Page, error) {
    if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
       return nil, err
    }
+   page.Data.BonanzaPage.guid = guid
    return &page.Data.BonanzaPage, nil
 }
 
 func (b Bonanza_Page) Video() (*Video, error) {
+   return b.Video_Context(context.Background())
+}
+
+func (b Bonanza_Page) Video_Context(ctx context.Context) (*Video, error) {
    var v video_request
    v.Device = "android"
    v.Device_ID = "android"
@@ -89,7 +139,7 @@ func (b Bonanza_Page) Video() (*Video, error) {
       return nil, err
    }
    var str strings.Builder
-   str.WriteString("http://access-cloudpath.media.nbcuni.com")
+   str.WriteString(Base.Access)
    str.WriteString("/access/vod/nbcuniversal/")
    str.WriteString(b.Name)
    req, err := http.NewRequest("POST", str.String(), bytes.NewReader(body))
@@ -100,7 +150,7 @@ func (b Bonanza_Page) Video() (*Video, error) {
       "Authorization": {authorization()},
       "Content-Type": {"application/json"},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -1,11 +1,18 @@
 package nbc
 
 import (
+   "encoding/json"
    "fmt"
+   "github.com/89z/mech/cassette"
+   "os"
    "testing"
    "time"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/nbc.json", &Client))
+}
+
 var guids = []int64{
    // nbc.com/botched/video/seeing-double/3049418
    3049418,
@@ -18,6 +25,7 @@ var guids = []int64{
 }
 
 func Test_Video(t *testing.T) {
+   cassette.Require(t)
    for _, guid := range guids {
       page, err := New_Bonanza_Page(guid)
       if err != nil {
@@ -31,3 +39,22 @@ func Test_Video(t *testing.T) {
       time.Sleep(time.Second)
    }
 }
+
+func Test_Bonanza_Page_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/9000199373.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var page struct {
+      Data struct {
+         BonanzaPage Bonanza_Page
+      }
+   }
+   if err := json.Unmarshal(buf, &page); err != nil {
+      t.Fatal(err)
+   }
+   name := page.Data.BonanzaPage.Analytics.ConvivaAssetName
+   if name != "[9000199373] Saturday Night Live - April 2 - Jerrod Carmichael" {
+      t.Fatal(name)
+   }
+}
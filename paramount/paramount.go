@@ -1,17 +1,32 @@
 package paramount
 
 import (
+   "context"
    "crypto/aes"
    "crypto/cipher"
    "encoding/base64"
    "encoding/hex"
    "encoding/json"
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/meta"
    "github.com/89z/rosso/http"
    "net/url"
+   "path"
    "strconv"
    "strings"
 )
 
+// paramountplus.com/shows/video/eyT_RYkqNuH_6ZYrepLtxkiPO1HA7dIU
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "paramount",
+      Hosts: []string{"paramountplus.com"},
+      ID: func(ref *url.URL) (string, error) {
+         return path.Base(ref.Path), nil
+      },
+   })
+}
+
 const secret_key = "302a6a0d70a7e9b967f91d39fef3e387816e3095925ae4537bce96063311f9c5"
 
 var app_secrets = map[string]string{
@@ -53,6 +68,18 @@ func new_token() (string, error) {
 
 var Client = http.Default_Client
 
+// Base_URL is the API of the Android app, and thePlatform, which has the
+// media
+type Base_URL struct {
+   App string
+   Platform string
+}
+
+var Base = Base_URL{
+   App: "https://www.paramountplus.com/apps-api/v3.0/androidphone",
+   Platform: "http://link.theplatform.com",
+}
+
 func (p Preview) Name() string {
    b := []byte(p.Title)
    if p.Season_Number >= 1 {
@@ -64,6 +91,16 @@ func (p Preview) Name() string {
    return string(b)
 }
 
+func (p Preview) Meta() meta.Data {
+   data := meta.Data{ID: p.GUID, Site: "paramount", Title: p.Title}
+   if p.Season_Number >= 1 {
+      data.Episode = p.Episode_Number
+      data.Season = strconv.FormatInt(p.Season_Number, 10)
+      data.Series = p.Title
+   }
+   return data
+}
+
 type Preview struct {
    Episode_Number string `json:"cbs$EpisodeNumber"`
    GUID string
@@ -80,19 +117,23 @@ func pad(b []byte) []byte {
 }
 
 func New_Session(guid string) (*Session, error) {
+   return New_Session_Context(context.Background(), guid)
+}
+
+func New_Session_Context(ctx context.Context, guid string) (*Session, error) {
    token, err := new_token()
    if err != nil {
       return nil, err
    }
    var buf strings.Builder
-   buf.WriteString("https://www.paramountplus.com/apps-api/v3.0/androidphone")
+   buf.WriteString(Base.App)
    buf.WriteString("/irdeto-control/anonymous-session-token.json")
    req, err := http.NewRequest("GET", buf.String(), nil)
    if err != nil {
       return nil, err
    }
    req.URL.RawQuery = "at=" + url.QueryEscape(token)
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -111,7 +152,8 @@ const (
 )
 
 func media(guid string) string {
-   b := []byte("http://link.theplatform.com/s/")
+   b := []byte(Base.Platform)
+   b = append(b, "/s/"...)
    b = append(b, sid...)
    b = append(b, "/media/guid/"...)
    b = strconv.AppendInt(b, aid, 10)
@@ -121,12 +163,16 @@ func media(guid string) string {
 }
 
 func New_Preview(guid string) (*Preview, error) {
+   return New_Preview_Context(context.Background(), guid)
+}
+
+func New_Preview_Context(ctx context.Context, guid string) (*Preview, error) {
    req, err := http.NewRequest("GET", media(guid), nil)
    if err != nil {
       return nil, err
    }
    req.URL.RawQuery = "format=preview"
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -1,11 +1,20 @@
 package paramount
 
 import (
+   "encoding/json"
    "fmt"
+   "github.com/89z/mech/cassette"
+   "net/http"
+   "net/http/httptest"
+   "os"
    "testing"
    "time"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/paramount.json", &Client))
+}
+
 var tests = map[test_type]string{
    {episode, dash_cenc}: "eyT_RYkqNuH_6ZYrepLtxkiPO1HA7dIU",
    {episode, stream_pack}: "622520382",
@@ -26,6 +35,7 @@ type test_type struct {
 }
 
 func Test_Preview(t *testing.T) {
+   cassette.Require(t)
    for _, test := range tests {
       preview, err := New_Preview(test)
       if err != nil {
@@ -35,3 +45,45 @@ func Test_Preview(t *testing.T) {
       time.Sleep(time.Second)
    }
 }
+
+func Test_Preview_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/Movie.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var preview Preview
+   if err := json.Unmarshal(buf, &preview); err != nil {
+      t.Fatal(err)
+   }
+   if preview.GUID != "tQk_Qooh5wUlxQqzj_4LiBO2m4iMrcPD" {
+      t.Fatal(preview.GUID)
+   }
+   // a movie has no season, so the name is only the title
+   if name := preview.Name(); name != "The SpongeBob Movie: Sponge On The Run" {
+      t.Fatal(name)
+   }
+}
+
+func Test_Base(t *testing.T) {
+   guid := tests[test_type{movie, dash_cenc}]
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.URL.Path != "/s/dJ5BDC/media/guid/2198311517/" + guid {
+            http.NotFound(w, r)
+            return
+         }
+         http.ServeFile(w, r, "testdata/Movie.json")
+      },
+   ))
+   defer server.Close()
+   base := Base
+   defer func() { Base = base }()
+   Base.Platform = server.URL
+   preview, err := New_Preview(guid)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if preview.GUID != guid {
+      t.Fatal(preview)
+   }
+}
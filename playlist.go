@@ -0,0 +1,205 @@
+package mech
+
+import (
+   "bufio"
+   "io"
+   "net/url"
+   "path"
+   "strconv"
+   "strings"
+   "time"
+)
+
+// attributes splits an attribute list such as
+//   METHOD=AES-128,URI="key.bin",IV=0x01
+// quoted values can hold commas
+func attributes(line string) map[string]string {
+   _, line, _ = strings.Cut(line, ":")
+   attr := make(map[string]string)
+   for line != "" {
+      var key, value string
+      key, line, _ = strings.Cut(line, "=")
+      if strings.HasPrefix(line, `"`) {
+         value, line, _ = strings.Cut(line[1:], `"`)
+         line = strings.TrimPrefix(line, ",")
+      } else {
+         value, line, _ = strings.Cut(line, ",")
+      }
+      attr[strings.TrimSpace(key)] = value
+   }
+   return attr
+}
+
+// master_attributes maps each variant and rendition URI of a master
+// playlist to its attribute list, as hls.Master drops some attributes
+func master_attributes(r io.Reader) (map[string]map[string]string, error) {
+   attrs := make(map[string]map[string]string)
+   var variant map[string]string
+   scan := bufio.NewScanner(r)
+   for scan.Scan() {
+      line := strings.TrimSpace(scan.Text())
+      switch {
+      case line == "":
+      case strings.HasPrefix(line, "#EXT-X-MEDIA:"):
+         attr := attributes(line)
+         if attr["URI"] != "" {
+            attrs[attr["URI"]] = attr
+         }
+      case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+         variant = attributes(line)
+      case !strings.HasPrefix(line, "#"):
+         if variant != nil {
+            attrs[line] = variant
+            variant = nil
+         }
+      }
+   }
+   if err := scan.Err(); err != nil {
+      return nil, err
+   }
+   return attrs, nil
+}
+
+// playlist is a media playlist, with the tags that hls.Scanner does not
+// keep
+type playlist struct {
+   duration time.Duration // sum of EXTINF
+   end_list bool
+   init segment // EXT-X-MAP, empty for none
+   key string
+   segments []segment
+   sequence int64
+   target_duration time.Duration
+}
+
+// byte_range reads n[@o] as a range of segment, where o defaults to the
+// byte after the previous range of the same resource
+func byte_range(value string, ref string, prev segment) (string, error) {
+   length, offset, ok := strings.Cut(value, "@")
+   n, err := strconv.ParseInt(length, 10, 64)
+   if err != nil {
+      return "", err
+   }
+   var start int64
+   if ok {
+      start, err = strconv.ParseInt(offset, 10, 64)
+      if err != nil {
+         return "", err
+      }
+   } else if prev.ref == ref && prev.byte_range != "" {
+      _, end, _ := parse_range("bytes=" + prev.byte_range)
+      start = end + 1
+   }
+   b := strconv.AppendInt(nil, start, 10)
+   b = append(b, '-')
+   b = strconv.AppendInt(b, start + n - 1, 10)
+   return string(b), nil
+}
+
+// ext is the extension of the output. With EXT-X-MAP the segments are
+// fragmented MP4, so variant is kept. Otherwise the segments name their
+// format, such as .ts or .aac.
+func (p playlist) ext(variant string) string {
+   if p.init.ref != "" || len(p.segments) == 0 {
+      return variant
+   }
+   ref, err := url.Parse(p.segments[0].ref)
+   if err != nil {
+      return variant
+   }
+   switch ext := path.Ext(ref.Path); ext {
+   case ".aac", ".ts":
+      return ext
+   }
+   return variant
+}
+
+func new_playlist(r io.Reader) (*playlist, error) {
+   var (
+      ads ad_state
+      extinf time.Duration // of the next segment
+      length string // EXT-X-BYTERANGE of the next segment
+      period int
+      periods []int // of each segment
+      play playlist
+      prev segment
+   )
+   scan := bufio.NewScanner(r)
+   for scan.Scan() {
+      line := strings.TrimSpace(scan.Text())
+      switch {
+      case line == "":
+      case !strings.HasPrefix(line, "#"):
+         seg := segment{ref: line, duration: extinf}
+         seg.ad = ads.mark(extinf)
+         extinf = 0
+         if length != "" {
+            var err error
+            seg.byte_range, err = byte_range(length, line, prev)
+            if err != nil {
+               return nil, err
+            }
+            length = ""
+         }
+         play.segments = append(play.segments, seg)
+         periods = append(periods, period)
+         prev = seg
+      case strings.HasPrefix(line, "#EXTINF:"):
+         _, value, _ := strings.Cut(line, ":")
+         value, _, _ = strings.Cut(value, ",")
+         sec, err := strconv.ParseFloat(value, 64)
+         if err != nil {
+            return nil, err
+         }
+         extinf = time.Duration(sec * float64(time.Second))
+         play.duration += extinf
+      case line == "#EXT-X-CUE-IN":
+         if ads.signal == ad_cue {
+            ads.signal = ""
+         }
+      case strings.HasPrefix(line, "#EXT-X-CUE-OUT:"), line == "#EXT-X-CUE-OUT":
+         ads.cue_out(line)
+      case strings.HasPrefix(line, "#EXT-X-DATERANGE:"):
+         ads.date_range(line)
+      case line == "#EXT-X-DISCONTINUITY":
+         period++
+      case line == "#EXT-X-ENDLIST":
+         play.end_list = true
+      case strings.HasPrefix(line, "#EXT-X-BYTERANGE:"):
+         _, length, _ = strings.Cut(line, ":")
+      case strings.HasPrefix(line, "#EXT-X-KEY:"):
+         play.key = attributes(line)["URI"]
+      case strings.HasPrefix(line, "#EXT-X-MAP:"):
+         attr := attributes(line)
+         play.init = segment{ref: attr["URI"]}
+         if value := attr["BYTERANGE"]; value != "" {
+            var err error
+            play.init.byte_range, err = byte_range(value, "", segment{})
+            if err != nil {
+               return nil, err
+            }
+         }
+      case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+         _, value, _ := strings.Cut(line, ":")
+         var err error
+         play.sequence, err = strconv.ParseInt(value, 10, 64)
+         if err != nil {
+            return nil, err
+         }
+      case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+         _, value, _ := strings.Cut(line, ":")
+         sec, err := strconv.ParseFloat(value, 64)
+         if err != nil {
+            return nil, err
+         }
+         play.target_duration = time.Duration(sec * float64(time.Second))
+      }
+   }
+   if err := scan.Err(); err != nil {
+      return nil, err
+   }
+   if !ads.signals {
+      play.mark_periods(periods)
+   }
+   return &play, nil
+}
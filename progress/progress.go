@@ -0,0 +1,129 @@
+package progress
+
+import (
+   "io"
+   "os"
+   "time"
+)
+
+type Kind int
+
+const (
+   Started Kind = iota
+   Segment_Done
+   Bytes_Written
+   Retried
+   Finished
+)
+
+var kinds = []string{
+   "Started", "Segment_Done", "Bytes_Written", "Retried", "Finished",
+}
+
+func (k Kind) String() string {
+   if k < 0 || int(k) >= len(kinds) {
+      return "Kind"
+   }
+   return kinds[k]
+}
+
+// Event is a change in a download. Total and ETA are zero until known; with
+// segments, Total is estimated from the segments done so far.
+type Event struct {
+   Kind Kind
+   Name string // the file written to
+   Bytes int64 // written so far
+   Total int64
+   Elapsed time.Duration
+   ETA time.Duration
+   Segment int // with Segment_Done, the index in the manifest
+   Segments int // zero for a live playlist
+   Attempt int // with Retried, the attempt that failed
+   Delay time.Duration // with Retried, the wait before the next attempt
+   URL string // with Retried
+   Err error // with Retried the cause, and with Finished nil on success
+}
+
+// Observer is told of each event. Events of one download come in order,
+// but Retried can come from the goroutines that fetch segments, and one
+// Observer can be given events of several downloads, so Observe must be safe
+// for concurrent use.
+type Observer interface {
+   Observe(Event)
+}
+
+// Default is told of the downloads that have no Observer
+var Default Observer = New_Terminal(os.Stderr)
+
+// Tracker counts the bytes written through it, and tells an Observer
+type Tracker struct {
+   event Event
+   observer Observer
+   read int64 // bytes of the segments done
+   segments int // done
+   start time.Time
+   w io.Writer
+}
+
+func new_tracker(w io.Writer, o Observer, name string) *Tracker {
+   if o == nil {
+      o = Default
+   }
+   t := Tracker{observer: o, start: time.Now(), w: w}
+   t.event.Name = name
+   return &t
+}
+
+// New_Bytes reports Started for a download of total bytes, zero if unknown
+func New_Bytes(w io.Writer, o Observer, name string, total int64) *Tracker {
+   t := new_tracker(w, o, name)
+   t.event.Total = total
+   t.send(Started)
+   return t
+}
+
+// New_Segments reports Started for a download of segments
+func New_Segments(w io.Writer, o Observer, name string, segments int) *Tracker {
+   t := new_tracker(w, o, name)
+   t.event.Segments = segments
+   t.send(Started)
+   return t
+}
+
+func (t *Tracker) send(kind Kind) {
+   t.event.Kind = kind
+   t.event.Elapsed = time.Since(t.start)
+   t.event.ETA = 0
+   if t.event.Bytes >= 1 && t.event.Total > t.event.Bytes {
+      left := float64(t.event.Total - t.event.Bytes) / float64(t.event.Bytes)
+      t.event.ETA = time.Duration(float64(t.event.Elapsed) * left)
+   }
+   t.observer.Observe(t.event)
+}
+
+// Segment reports Segment_Done for segment index, and bytes is its size as
+// downloaded
+func (t *Tracker) Segment(index int, bytes int64) {
+   t.read += bytes
+   t.segments++
+   if t.event.Segments >= 1 {
+      t.event.Total = int64(t.event.Segments) * t.read / int64(t.segments)
+   }
+   t.event.Segment = index
+   t.send(Segment_Done)
+}
+
+// Write reports Bytes_Written
+func (t *Tracker) Write(buf []byte) (int, error) {
+   n, err := t.w.Write(buf)
+   t.event.Bytes += int64(n)
+   t.send(Bytes_Written)
+   return n, err
+}
+
+// Finish reports Finished, and returns err
+func (t *Tracker) Finish(err error) error {
+   t.event.Err = err
+   t.send(Finished)
+   return err
+}
@@ -0,0 +1,49 @@
+package progress
+
+import (
+   "bytes"
+   "errors"
+   "io"
+   "testing"
+)
+
+type events []Event
+
+func (e *events) Observe(ev Event) {
+   *e = append(*e, ev)
+}
+
+func Test_Segments(t *testing.T) {
+   var got events
+   pro := New_Segments(io.Discard, &got, "file", 4)
+   for i := 0; i < 2; i++ {
+      pro.Segment(i, 100)
+      pro.Write(make([]byte, 100))
+   }
+   pro.Finish(nil)
+   kinds := []Kind{
+      Started, Segment_Done, Bytes_Written, Segment_Done, Bytes_Written,
+      Finished,
+   }
+   if len(got) != len(kinds) {
+      t.Fatal(got)
+   }
+   for i, kind := range kinds {
+      if got[i].Kind != kind {
+         t.Fatal(i, got[i].Kind)
+      }
+   }
+   last := got[len(got)-1]
+   if last.Bytes != 200 || last.Total != 400 || last.Name != "file" {
+      t.Fatal(last)
+   }
+}
+
+func Test_Terminal(t *testing.T) {
+   var buf bytes.Buffer
+   term := New_Terminal(&buf)
+   term.Observe(Event{Kind: Retried, Delay: 2e9, Err: errors.New("503")})
+   if buf.String() != "Retry 2s\n" {
+      t.Fatalf("%q", buf.String())
+   }
+}
@@ -0,0 +1,52 @@
+package progress
+
+import (
+   "github.com/89z/rosso/strconv"
+   "io"
+   "sync"
+   "time"
+)
+
+// Terminal writes a line for each second of a download, and for each retry
+type Terminal struct {
+   laps map[string]time.Time
+   mu sync.Mutex
+   w io.Writer
+}
+
+func New_Terminal(w io.Writer) *Terminal {
+   return &Terminal{laps: make(map[string]time.Time), w: w}
+}
+
+func (t *Terminal) Observe(e Event) {
+   t.mu.Lock()
+   defer t.mu.Unlock()
+   switch e.Kind {
+   case Started:
+      t.laps[e.Name] = time.Now()
+   case Bytes_Written:
+      lap := t.laps[e.Name]
+      if time.Since(lap) < time.Second {
+         return
+      }
+      t.laps[e.Name] = time.Now()
+      var b []byte
+      if e.Total >= 1 {
+         b = strconv.NewRatio(e.Bytes, e.Total).AppendPercent(b)
+         b = append(b, "   "...)
+      }
+      b = strconv.AppendSize(b, e.Bytes)
+      b = append(b, "   "...)
+      b = strconv.NewRatio(e.Bytes, e.Elapsed.Seconds()).AppendRate(b)
+      if e.ETA >= 1 {
+         b = append(b, "   "...)
+         b = append(b, e.ETA.Round(time.Second).String()...)
+      }
+      b = append(b, '\n')
+      t.w.Write(b)
+   case Retried:
+      t.w.Write([]byte("Retry " + e.Delay.String() + "\n"))
+   case Finished:
+      delete(t.laps, e.Name)
+   }
+}
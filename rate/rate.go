@@ -0,0 +1,124 @@
+package rate
+
+import (
+   "io"
+   "sync"
+   "time"
+)
+
+// Global is shared by every download in the process. It is nil, for no
+// limit, until set with New_Limiter.
+var Global *Limiter
+
+// Limiter is a token bucket, in bytes. A nil Limiter does not limit.
+type Limiter struct {
+   last time.Time
+   mu sync.Mutex
+   rate float64 // bytes per second, also the bucket size
+   tokens float64
+}
+
+// New_Limiter returns nil, for no limit, if bytes is less than one
+func New_Limiter(bytes int64) *Limiter {
+   if bytes <= 0 {
+      return nil
+   }
+   return &Limiter{rate: float64(bytes), tokens: float64(bytes)}
+}
+
+// Wait blocks until n bytes can pass. The bytes are taken from the bucket
+// under the lock, and the sleep comes after, so that callers that share the
+// Limiter do not wait on each other.
+func (l *Limiter) Wait(n int) {
+   if l == nil {
+      return
+   }
+   l.mu.Lock()
+   now := time.Now()
+   if !l.last.IsZero() {
+      l.tokens += now.Sub(l.last).Seconds() * l.rate
+      if l.tokens > l.rate {
+         l.tokens = l.rate
+      }
+   }
+   l.last = now
+   l.tokens -= float64(n)
+   var delay time.Duration
+   if l.tokens < 0 {
+      delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+   }
+   l.mu.Unlock()
+   time.Sleep(delay)
+}
+
+// writes are split so that one large write does not arrive all at once
+const chunk = 32 * 1024
+
+type Writer struct {
+   limits []*Limiter
+   w io.Writer
+}
+
+func non_nil(limits []*Limiter) []*Limiter {
+   var out []*Limiter
+   for _, limit := range limits {
+      if limit != nil {
+         out = append(out, limit)
+      }
+   }
+   return out
+}
+
+// New_Writer waits on each non-nil Limiter before each write to w
+func New_Writer(w io.Writer, limits ...*Limiter) *Writer {
+   return &Writer{limits: non_nil(limits), w: w}
+}
+
+func (w Writer) Write(p []byte) (int, error) {
+   if w.limits == nil {
+      return w.w.Write(p)
+   }
+   var n int
+   for len(p) >= 1 {
+      size := len(p)
+      if size > chunk {
+         size = chunk
+      }
+      for _, limit := range w.limits {
+         limit.Wait(size)
+      }
+      m, err := w.w.Write(p[:size])
+      n += m
+      if err != nil {
+         return n, err
+      }
+      p = p[size:]
+   }
+   return n, nil
+}
+
+type Reader struct {
+   limits []*Limiter
+   r io.Reader
+}
+
+// New_Reader waits on each non-nil Limiter after each read from r. Reads
+// are no larger than one chunk, so a reader that is held back also holds
+// back the connection under it.
+func New_Reader(r io.Reader, limits ...*Limiter) *Reader {
+   return &Reader{limits: non_nil(limits), r: r}
+}
+
+func (r Reader) Read(p []byte) (int, error) {
+   if r.limits == nil {
+      return r.r.Read(p)
+   }
+   if len(p) > chunk {
+      p = p[:chunk]
+   }
+   n, err := r.r.Read(p)
+   for _, limit := range r.limits {
+      limit.Wait(n)
+   }
+   return n, err
+}
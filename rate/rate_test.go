@@ -0,0 +1,49 @@
+package rate
+
+import (
+   "bytes"
+   "io"
+   "testing"
+   "time"
+)
+
+func Test_Writer(t *testing.T) {
+   limit := New_Limiter(100_000)
+   start := time.Now()
+   // the first 100 KB is the bucket, and the next 50 KB takes half a second
+   w := New_Writer(io.Discard, limit, nil)
+   n, err := w.Write(make([]byte, 150_000))
+   if err != nil {
+      t.Fatal(err)
+   }
+   if n != 150_000 {
+      t.Fatal(n)
+   }
+   if elapsed := time.Since(start); elapsed < 400 * time.Millisecond {
+      t.Fatal(elapsed)
+   }
+}
+
+func Test_Reader(t *testing.T) {
+   limit := New_Limiter(100_000)
+   start := time.Now()
+   r := New_Reader(bytes.NewReader(make([]byte, 150_000)), nil, limit)
+   n, err := io.Copy(io.Discard, r)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if n != 150_000 {
+      t.Fatal(n)
+   }
+   if elapsed := time.Since(start); elapsed < 400 * time.Millisecond {
+      t.Fatal(elapsed)
+   }
+}
+
+func Test_Nil(t *testing.T) {
+   if New_Limiter(0) != nil {
+      t.Fatal("zero")
+   }
+   var limit *Limiter
+   limit.Wait(1)
+}
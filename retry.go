@@ -0,0 +1,287 @@
+package mech
+
+import (
+   "context"
+   "errors"
+   "github.com/89z/mech/progress"
+   "io"
+   "math/rand"
+   "net"
+   "net/http"
+   "os"
+   "strconv"
+   "strings"
+   "time"
+)
+
+// Retry is a policy for requests that fail with a transient error. The zero
+// value makes a single attempt. Zero fields otherwise fall back to the
+// defaults below.
+type Retry struct {
+   Attempts int
+   Delay time.Duration // first backoff, doubled after each attempt
+   Max_Delay time.Duration
+   Status []int // retryable status codes
+   Errors []error // retryable errors, matched with errors.Is
+   Round_Tripper http.RoundTripper
+   Progress progress.Observer // told of each retry, nil for progress.Default
+}
+
+var (
+   retry_delay = time.Second
+   retry_max_delay = 30 * time.Second
+   retry_status = []int{
+      http.StatusTooManyRequests,
+      http.StatusInternalServerError,
+      http.StatusBadGateway,
+      http.StatusServiceUnavailable,
+      http.StatusGatewayTimeout,
+   }
+   retry_errors = append(
+      []error{io.ErrUnexpectedEOF, os.ErrDeadlineExceeded}, retry_errnos...,
+   )
+)
+
+func (r Retry) round_tripper() http.RoundTripper {
+   if r.Round_Tripper != nil {
+      return r.Round_Tripper
+   }
+   return http.DefaultTransport
+}
+
+func (r Retry) retry_status(code int) bool {
+   status := r.Status
+   if status == nil {
+      status = retry_status
+   }
+   for _, s := range status {
+      if s == code {
+         return true
+      }
+   }
+   return false
+}
+
+func (r Retry) retry_error(err error) bool {
+   errs := r.Errors
+   if errs == nil {
+      errs = retry_errors
+   }
+   for _, target := range errs {
+      if errors.Is(err, target) {
+         return true
+      }
+   }
+   var net_err net.Error
+   if errors.As(err, &net_err) {
+      return net_err.Timeout()
+   }
+   return false
+}
+
+// backoff sleeps before the next attempt, with jitter over the upper half
+// of the exponential delay. The sleep ends early if ctx is done.
+func (r Retry) backoff(
+   ctx context.Context, ref string, attempt int, err error,
+) {
+   delay, max_delay := r.Delay, r.Max_Delay
+   if delay <= 0 {
+      delay = retry_delay
+   }
+   if max_delay <= 0 {
+      max_delay = retry_max_delay
+   }
+   for i := 1; i < attempt && delay < max_delay; i++ {
+      delay *= 2
+   }
+   if delay > max_delay {
+      delay = max_delay
+   }
+   delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2) + 1))
+   observer := r.Progress
+   if observer == nil {
+      observer = progress.Default
+   }
+   observer.Observe(progress.Event{
+      Kind: progress.Retried,
+      Attempt: attempt,
+      Delay: delay,
+      URL: ref,
+      Err: err,
+   })
+   select {
+   case <-time.After(delay):
+   case <-ctx.Done():
+   }
+}
+
+func (r Retry) rewind(req *http.Request) (*http.Request, bool) {
+   if req.Body == nil || req.Body == http.NoBody {
+      return req, true
+   }
+   if req.GetBody == nil {
+      return nil, false
+   }
+   body, err := req.GetBody()
+   if err != nil {
+      return nil, false
+   }
+   clone := req.Clone(req.Context())
+   clone.Body = body
+   return clone, true
+}
+
+// RoundTrip sends req, and sends it again after a retryable error or
+// status. If a GET body fails partway, the rest is requested with a Range
+// header, so the bytes already read are not downloaded again.
+func (r Retry) RoundTrip(req *http.Request) (*http.Response, error) {
+   res, attempt, err := r.round_trip(req, 1)
+   if err != nil {
+      return nil, err
+   }
+   if r.Attempts >= 2 && req.Method == "GET" {
+      res.Body = &retry_body{
+         ReadCloser: res.Body, attempt: attempt, req: req, retry: r,
+      }
+   }
+   return res, nil
+}
+
+// round_trip also returns the number of the last attempt, so that a body
+// resumed later spends what is left of the same budget
+func (r Retry) round_trip(
+   req *http.Request, attempt int,
+) (*http.Response, int, error) {
+   for ; ; attempt++ {
+      send, ok := r.rewind(req)
+      if !ok {
+         res, err := r.round_tripper().RoundTrip(req)
+         return res, attempt, err
+      }
+      res, err := r.round_tripper().RoundTrip(send)
+      last := attempt >= r.Attempts || req.Context().Err() != nil
+      if err != nil {
+         if last || !r.retry_error(err) {
+            return nil, attempt, err
+         }
+      } else {
+         if last || !r.retry_status(res.StatusCode) {
+            return res, attempt, nil
+         }
+         res.Body.Close()
+         err = errors.New(res.Status)
+      }
+      r.backoff(req.Context(), req.URL.String(), attempt, err)
+   }
+}
+
+// Transport returns a Transport that sends every request through the
+// policy, for use with the Client of each site package:
+//
+//   roku.Client = roku.Client.Transport(policy.Transport())
+func (r Retry) Transport() *http.Transport {
+   return transport(r)
+}
+
+// the registered protocols take every request, so the Transport only wraps
+// the RoundTripper
+func transport(rt http.RoundTripper) *http.Transport {
+   tr := new(http.Transport)
+   tr.RegisterProtocol("http", rt)
+   tr.RegisterProtocol("https", rt)
+   return tr
+}
+
+type retry_body struct {
+   io.ReadCloser
+   attempt int // attempts used so far, by the request and each resume
+   read int64
+   req *http.Request
+   retry Retry
+}
+
+func (r *retry_body) Read(buf []byte) (int, error) {
+   n, err := r.ReadCloser.Read(buf)
+   r.read += int64(n)
+   if err == nil || err == io.EOF || !r.retry.retry_error(err) {
+      return n, err
+   }
+   if r.attempt >= r.retry.Attempts {
+      return n, err
+   }
+   if err := r.resume(err); err != nil {
+      return n, err
+   }
+   return n, nil
+}
+
+// resume requests the rest of the body, starting after the bytes already
+// read
+func (r *retry_body) resume(cause error) error {
+   r.ReadCloser.Close()
+   r.retry.backoff(r.req.Context(), r.req.URL.String(), r.attempt, cause)
+   var start, end int64 = 0, -1
+   if value := r.req.Header.Get("Range"); value != "" {
+      var ok bool
+      start, end, ok = parse_range(value)
+      if !ok {
+         return errors.New("Range " + value)
+      }
+   }
+   req := r.req.Clone(r.req.Context())
+   b := []byte("bytes=")
+   b = strconv.AppendInt(b, start + r.read, 10)
+   b = append(b, '-')
+   if end >= 0 {
+      b = strconv.AppendInt(b, end, 10)
+   }
+   req.Header.Set("Range", string(b))
+   res, attempt, err := r.retry.round_trip(req, r.attempt + 1)
+   if err != nil {
+      return err
+   }
+   r.attempt = attempt
+   switch res.StatusCode {
+   case http.StatusPartialContent:
+   case http.StatusOK:
+      if end >= 0 {
+         res.Body.Close()
+         return errors.New(res.Status)
+      }
+      // Range was ignored, so skip what we already have
+      _, err := io.CopyN(io.Discard, res.Body, r.read)
+      if err != nil {
+         res.Body.Close()
+         return err
+      }
+   default:
+      res.Body.Close()
+      return errors.New(res.Status)
+   }
+   r.ReadCloser = res.Body
+   return nil
+}
+
+// bytes=start-end, where end is optional
+func parse_range(value string) (int64, int64, bool) {
+   if !strings.HasPrefix(value, "bytes=") {
+      return 0, 0, false
+   }
+   value = strings.TrimPrefix(value, "bytes=")
+   first, last, ok := strings.Cut(value, "-")
+   if !ok {
+      return 0, 0, false
+   }
+   start, err := strconv.ParseInt(first, 10, 64)
+   if err != nil {
+      return 0, 0, false
+   }
+   if last == "" {
+      return start, -1, true
+   }
+   end, err := strconv.ParseInt(last, 10, 64)
+   if err != nil {
+      return 0, 0, false
+   }
+   return start, end, true
+}
@@ -0,0 +1,103 @@
+package mech
+
+import (
+   "io"
+   "net/http"
+   "net/http/httptest"
+   "testing"
+   "time"
+)
+
+const retry_body_test = "0123456789"
+
+func Test_Retry(t *testing.T) {
+   var requests []string
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         requests = append(requests, r.Header.Get("Range"))
+         switch len(requests) {
+         case 1:
+            w.WriteHeader(http.StatusServiceUnavailable)
+         case 2:
+            // promise the whole body, then hang up after half of it
+            w.Header().Set("Content-Length", "10")
+            w.Write([]byte(retry_body_test[:5]))
+            w.(http.Flusher).Flush()
+            conn, _, err := w.(http.Hijacker).Hijack()
+            if err != nil {
+               t.Error(err)
+            }
+            conn.Close()
+         default:
+            w.WriteHeader(http.StatusPartialContent)
+            w.Write([]byte(retry_body_test[5:]))
+         }
+      },
+   ))
+   defer server.Close()
+   policy := Retry{Attempts: 3, Delay: time.Millisecond}
+   res, err := client.Level(0).Transport(policy.Transport()).Get(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer res.Body.Close()
+   buf, err := io.ReadAll(res.Body)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if string(buf) != retry_body_test {
+      t.Fatal(string(buf))
+   }
+   if requests[2] != "bytes=5-" {
+      t.Fatal(requests)
+   }
+}
+
+// a failed request and a failed body share one budget of attempts
+func Test_Retry_Budget(t *testing.T) {
+   var requests int
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         requests++
+         if requests == 1 {
+            w.WriteHeader(http.StatusServiceUnavailable)
+            return
+         }
+         w.Header().Set("Content-Length", "10")
+         w.Write([]byte(retry_body_test[:5]))
+         w.(http.Flusher).Flush()
+         conn, _, err := w.(http.Hijacker).Hijack()
+         if err != nil {
+            t.Error(err)
+         }
+         conn.Close()
+      },
+   ))
+   defer server.Close()
+   policy := Retry{Attempts: 2, Delay: time.Millisecond}
+   res, err := client.Level(0).Transport(policy.Transport()).Get(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer res.Body.Close()
+   if _, err := io.ReadAll(res.Body); err == nil {
+      t.Fatal("body was resumed past the budget")
+   }
+   if requests != 2 {
+      t.Fatal(requests)
+   }
+}
+
+var range_tests = map[string][2]int64{
+   "bytes=0-": {0, -1},
+   "bytes=10-19": {10, 19},
+}
+
+func Test_Range(t *testing.T) {
+   for value, want := range range_tests {
+      start, end, ok := parse_range(value)
+      if !ok || start != want[0] || end != want[1] {
+         t.Fatal(value, start, end)
+      }
+   }
+}
@@ -1,6 +1,7 @@
 package roku
 
 import (
+   "github.com/89z/mech/cassette"
    "github.com/89z/mech/widevine"
    "os"
    "testing"
@@ -14,17 +15,18 @@ const (
 )
 
 func Test_Post(t *testing.T) {
+   cassette.Require(t)
    home, err := os.UserHomeDir()
    if err != nil {
       t.Fatal(err)
    }
    private_key, err := os.ReadFile(home + "/mech/private_key.pem")
    if err != nil {
-      t.Fatal(err)
+      t.Skip(err)
    }
    client_ID, err := os.ReadFile(home + "/mech/client_id.bin")
    if err != nil {
-      t.Fatal(err)
+      t.Skip(err)
    }
    key_ID, err := widevine.Key_ID(raw_key_ID)
    if err != nil {
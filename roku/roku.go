@@ -2,15 +2,29 @@ package roku
 
 import (
    "bytes"
+   "context"
    "errors"
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/meta"
    "github.com/89z/rosso/http"
    "github.com/89z/rosso/json"
    "io"
    "net/url"
+   "path"
    "strings"
    "time"
 )
 
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "roku",
+      Hosts: []string{"therokuchannel.roku.com"},
+      ID: func(ref *url.URL) (string, error) {
+         return path.Base(ref.Path), nil
+      },
+   })
+}
+
 func (c Content) String() string {
    var buf strings.Builder
    write := func(str string) {
@@ -39,12 +53,25 @@ func (c Content) String() string {
 
 var Client = http.Default_Client
 
+// Base_URL is the origin of The Roku Channel
+type Base_URL struct {
+   Site string
+}
+
+var Base = Base_URL{Site: "https://therokuchannel.roku.com"}
+
 type Cross_Site struct {
    cookie *http.Cookie // has own String method
    token string
 }
 
 func (c Cross_Site) Playback(id string) (*Playback, error) {
+   return c.Playback_Context(context.Background(), id)
+}
+
+func (c Cross_Site) Playback_Context(
+   ctx context.Context, id string,
+) (*Playback, error) {
    buf, err := json.Marshal(map[string]string{
       "mediaFormat": "mpeg-dash",
       "rokuId": id,
@@ -53,7 +80,7 @@ func (c Cross_Site) Playback(id string) (*Playback, error) {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", "https://therokuchannel.roku.com/api/v3/playback",
+      "POST", Base.Site + "/api/v3/playback",
       bytes.NewReader(buf),
    )
    if err != nil {
@@ -64,7 +91,7 @@ func (c Cross_Site) Playback(id string) (*Playback, error) {
       "Content-Type": {"application/json"},
    }
    req.AddCookie(c.cookie)
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -90,7 +117,25 @@ func (c Content) Name() string {
    return buf.String()
 }
 
+// Get_Meta is named so, as Content has a Meta field
+func (c Content) Get_Meta() meta.Data {
+   data := meta.Data{ID: c.Meta.ID, Site: "roku", Title: c.Title}
+   if len(c.ReleaseDate) >= 10 {
+      data.Date = c.ReleaseDate[:10]
+   }
+   if c.Meta.MediaType == "episode" {
+      data.Episode = c.EpisodeNumber
+      data.Season = c.SeasonNumber
+      data.Series = c.Series.Title
+   }
+   return data
+}
+
 func New_Content(id string) (*Content, error) {
+   return New_Content_Context(context.Background(), id)
+}
+
+func New_Content_Context(ctx context.Context, id string) (*Content, error) {
    var ref url.URL
    ref.Scheme = "https"
    ref.Host = "content.sr.roku.com"
@@ -110,9 +155,14 @@ func New_Content(id string) (*Content, error) {
       }, ",")},
    }.Encode()
    var buf strings.Builder
-   buf.WriteString("https://therokuchannel.roku.com/api/v2/homescreen/content/")
+   buf.WriteString(Base.Site)
+   buf.WriteString("/api/v2/homescreen/content/")
    buf.WriteString(url.PathEscape(ref.String()))
-   res, err := Client.Get(buf.String())
+   req, err := http.NewRequest("GET", buf.String(), nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -159,8 +209,16 @@ func (c Content) HLS() (*Video, error) {
 }
 
 func New_Cross_Site() (*Cross_Site, error) {
+   return New_Cross_Site_Context(context.Background())
+}
+
+func New_Cross_Site_Context(ctx context.Context) (*Cross_Site, error) {
    // this has smaller body than www.roku.com
-   res, err := Client.Get("https://therokuchannel.roku.com")
+   req, err := http.NewRequest("GET", Base.Site, nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -1,14 +1,22 @@
 package roku
 
 import (
+   "encoding/json"
    "fmt"
+   "github.com/89z/mech/cassette"
+   "os"
    "testing"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/roku.json", &Client))
+}
+
 // therokuchannel.roku.com/watch/105c41ea75775968b670fbb26978ed76
 const id = "105c41ea75775968b670fbb26978ed76"
 
 func Test_Video(t *testing.T) {
+   cassette.Require(t)
    con, err := New_Content(id)
    if err != nil {
       t.Fatal(err)
@@ -20,3 +28,20 @@ func Test_Video(t *testing.T) {
    }
    fmt.Printf("%+v\n", video)
 }
+
+func Test_Content_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/episode.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var con Content
+   if err := json.Unmarshal(buf, &con); err != nil {
+      t.Fatal(err)
+   }
+   if name := con.Name(); name != "House-4-4-Guardian Angels" {
+      t.Fatal(name)
+   }
+   if len(con.ViewOptions) != 1 {
+      t.Fatal(con.ViewOptions)
+   }
+}
@@ -0,0 +1,90 @@
+package mech
+
+import (
+   "bytes"
+   "context"
+   "errors"
+   "github.com/89z/rosso/dash"
+   "github.com/edgeware/mp4ff/mp4"
+   "io"
+   "strconv"
+)
+
+// segment_base is a representation in one file, with a sidx box at
+// indexRange that gives the byte range of each subsegment
+type segment_base struct {
+   Index_Range string `xml:"indexRange,attr"`
+   Initialization struct {
+      Range string `xml:"range,attr"`
+   }
+}
+
+// dash_segments returns the initialization and media segments of item, from
+// SegmentTemplate or else SegmentBase
+func (s Stream) dash_segments(
+   ctx context.Context, item dash.Representation,
+) (segment, []segment, error) {
+   if item.SegmentTemplate != nil {
+      return segment{ref: item.Initialization()}, whole(item.Media()), nil
+   }
+   rep := s.mpd[item.ID]
+   if rep.SegmentBase == nil || rep.BaseURL == "" {
+      return segment{}, nil, errors.New("no segments for " + item.ID)
+   }
+   index := rep.SegmentBase.Index_Range
+   start, end, ok := parse_range("bytes=" + index)
+   if !ok || start < 1 || end < start {
+      return segment{}, nil, errors.New("indexRange " + index)
+   }
+   body, err := get_segment(
+      ctx, s.http_client(), s.base, segment{ref: rep.BaseURL, byte_range: index},
+   )
+   if err != nil {
+      return segment{}, nil, err
+   }
+   media, err := sidx_segments(rep.BaseURL, body, end + 1)
+   if err != nil {
+      return segment{}, nil, err
+   }
+   init := segment{
+      ref: rep.BaseURL, byte_range: rep.SegmentBase.Initialization.Range,
+   }
+   if init.byte_range == "" {
+      init.byte_range = "0-" + strconv.FormatInt(start - 1, 10)
+   }
+   return init, media, nil
+}
+
+// sidx_segments reads the sidx box in index, and returns a segment for each
+// subsegment of ref. offset is the first byte after the sidx box.
+func sidx_segments(ref string, index []byte, offset int64) ([]segment, error) {
+   var (
+      pos uint64
+      sidx *mp4.SidxBox
+   )
+   for sidx == nil {
+      box, err := mp4.DecodeBox(pos, bytes.NewReader(index[pos:]))
+      if err == io.EOF {
+         return nil, errors.New("sidx not found")
+      }
+      if err != nil {
+         return nil, err
+      }
+      sidx, _ = box.(*mp4.SidxBox)
+      pos += box.Size()
+   }
+   offset += int64(sidx.FirstOffset)
+   var segs []segment
+   for _, sub := range sidx.SidxRefs {
+      if sub.ReferenceType == 1 {
+         return nil, errors.New("sidx that references sidx")
+      }
+      end := offset + int64(sub.ReferencedSize)
+      b := strconv.AppendInt(nil, offset, 10)
+      b = append(b, '-')
+      b = strconv.AppendInt(b, end - 1, 10)
+      segs = append(segs, segment{ref: ref, byte_range: string(b)})
+      offset = end
+   }
+   return segs, nil
+}
@@ -0,0 +1,111 @@
+package mech
+
+import (
+   "bytes"
+   "fmt"
+   "github.com/edgeware/mp4ff/mp4"
+   "net/http"
+   "net/http/httptest"
+   "os"
+   "strings"
+   "testing"
+   "time"
+)
+
+const segment_base_mpd = `<MPD mediaPresentationDuration="PT3S">
+   <Period>
+      <AdaptationSet mimeType="audio/mp4">
+         <Representation id="audio" bandwidth="1">
+            <BaseURL>audio.mp4</BaseURL>
+            <SegmentBase indexRange="%v-%v">
+               <Initialization range="0-%v"/>
+            </SegmentBase>
+         </Representation>
+      </AdaptationSet>
+   </Period>
+</MPD>`
+
+// one file with init, sidx, and a fragment for each second
+func segment_base_file() ([]byte, []byte, string, error) {
+   var init, frags bytes.Buffer
+   moov := mp4.CreateEmptyInit()
+   moov.AddEmptyTrack(48000, "audio", "und")
+   if err := moov.Encode(&init); err != nil {
+      return nil, nil, "", err
+   }
+   sidx := &mp4.SidxBox{ReferenceID: 1, Timescale: 48000}
+   for i := 0; i < 3; i++ {
+      frag, err := mp4.CreateFragment(uint32(i + 1), 1)
+      if err != nil {
+         return nil, nil, "", err
+      }
+      frag.AddFullSample(mp4.FullSample{
+         Sample: mp4.Sample{Dur: 48000, Size: 1},
+         DecodeTime: uint64(i) * 48000,
+         Data: []byte{byte(i)},
+      })
+      start := frags.Len()
+      if err := frag.Encode(&frags); err != nil {
+         return nil, nil, "", err
+      }
+      sidx.SidxRefs = append(sidx.SidxRefs, mp4.SidxRef{
+         ReferencedSize: uint32(frags.Len() - start),
+         SubSegmentDuration: 48000,
+         StartsWithSAP: 1,
+      })
+   }
+   var index bytes.Buffer
+   if err := sidx.Encode(&index); err != nil {
+      return nil, nil, "", err
+   }
+   mpd := fmt.Sprintf(
+      segment_base_mpd, init.Len(), init.Len() + index.Len() - 1,
+      init.Len() - 1,
+   )
+   file := bytes.Join([][]byte{init.Bytes(), index.Bytes(), frags.Bytes()}, nil)
+   want := bytes.Join([][]byte{init.Bytes(), frags.Bytes()}, nil)
+   return file, want, mpd, nil
+}
+
+func Test_Segment_Base(t *testing.T) {
+   file, want, mpd, err := segment_base_file()
+   if err != nil {
+      t.Fatal(err)
+   }
+   var ranges []string
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         switch r.URL.Path {
+         case "/index.mpd":
+            w.Write([]byte(mpd))
+         case "/audio.mp4":
+            ranges = append(ranges, r.Header.Get("Range"))
+            http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(file))
+         }
+      },
+   ))
+   defer server.Close()
+   chdir(t)
+   var str Stream
+   str.Name = "segment_base"
+   str.Progress = new(observer)
+   str.Verify = true
+   items, err := str.DASH(server.URL + "/index.mpd")
+   if err != nil {
+      t.Fatal(err)
+   }
+   if err := str.DASH_Get(items, 0); err != nil {
+      t.Fatal(err)
+   }
+   got, err := os.ReadFile("segment_base.m4a")
+   if err != nil {
+      t.Fatal(err)
+   }
+   if !bytes.Equal(got, want) {
+      t.Fatal(len(got), len(want))
+   }
+   // index, initialization, and three subsegments
+   if len(ranges) != 5 || !strings.HasPrefix(ranges[4], "bytes=") {
+      t.Fatal(ranges)
+   }
+}
@@ -0,0 +1,298 @@
+package mech
+
+import (
+   "errors"
+   "github.com/89z/rosso/dash"
+   "github.com/89z/rosso/hls"
+   "strconv"
+   "strings"
+)
+
+var selector_ops = []string{"<=", ">=", "~", "<", ">", "="}
+
+var selector_fields = map[string]bool{
+   "bandwidth": true,
+   "codecs": false,
+   "height": true,
+   "lang": false,
+   "name": false,
+   "role": false,
+   "type": false,
+   "width": true,
+}
+
+type rule struct {
+   field string
+   kind string // video, audio or text, empty for every kind
+   number int64
+   op string
+   values []string
+}
+
+// Selector picks a track with rules separated by commas, such as
+//   video.height<=1080,codecs=avc1|hvc1,audio.lang=en|*,bandwidth<=5000000
+// Comparisons of bandwidth, height or width filter the tracks, and ~ prefers
+// the nearest value. Values separated by | are preferences in order, and *
+// matches anything; codecs and lang match by prefix. A rule applies to the
+// tracks that have the field, or only to video, audio or text tracks when
+// prefixed so. Ties go to the highest bandwidth. The zero Selector is
+// unset, and leaves the choice to the command.
+type Selector struct {
+   rules []rule
+   text string
+}
+
+func New_Selector(text string) (*Selector, error) {
+   var sel Selector
+   if err := sel.Set(text); err != nil {
+      return nil, err
+   }
+   return &sel, nil
+}
+
+// Set implements flag.Value
+func (s *Selector) Set(text string) error {
+   s.rules = nil
+   s.text = text
+   for _, clause := range strings.Split(text, ",") {
+      clause = strings.TrimSpace(clause)
+      if clause == "" {
+         continue
+      }
+      var r rule
+      for _, op := range selector_ops {
+         before, after, ok := strings.Cut(clause, op)
+         if ok {
+            r.field, r.op = strings.ToLower(before), op
+            r.values = strings.Split(strings.ToLower(after), "|")
+            break
+         }
+      }
+      if r.op == "" {
+         return errors.New("selector " + strconv.Quote(clause))
+      }
+      if kind, field, ok := strings.Cut(r.field, "."); ok {
+         r.kind, r.field = kind, field
+      }
+      switch r.kind {
+      case "", "video", "audio", "text":
+      default:
+         return errors.New("selector kind " + strconv.Quote(r.kind))
+      }
+      numeric, ok := selector_fields[r.field]
+      if !ok {
+         return errors.New("selector field " + strconv.Quote(r.field))
+      }
+      if numeric {
+         var err error
+         r.number, err = strconv.ParseInt(r.values[0], 10, 64)
+         if err != nil {
+            return err
+         }
+      } else if r.op != "=" {
+         return errors.New("selector " + strconv.Quote(clause))
+      }
+      s.rules = append(s.rules, r)
+   }
+   return nil
+}
+
+func (s Selector) String() string {
+   return s.text
+}
+
+// track is an Info_Item with what the rules need
+type track struct {
+   height int64
+   item Info_Item
+   kind string
+   width int64
+}
+
+func new_track(item Info_Item) track {
+   t := track{item: item}
+   width, height, _ := strings.Cut(item.Resolution, "x")
+   t.width, _ = strconv.ParseInt(width, 10, 64)
+   t.height, _ = strconv.ParseInt(height, 10, 64)
+   typ := strings.ToUpper(item.Type)
+   switch {
+   case strings.HasPrefix(item.Mime_Type, "video/"), typ == "VIDEO":
+      t.kind = "video"
+   case strings.HasPrefix(item.Mime_Type, "audio/"), typ == "AUDIO":
+      t.kind = "audio"
+   case strings.HasPrefix(item.Mime_Type, "text/"),
+      strings.HasPrefix(item.Mime_Type, "application/"),
+      typ == "SUBTITLES", typ == "CLOSED-CAPTIONS":
+      t.kind = "text"
+   }
+   return t
+}
+
+func (t track) number(field string) int64 {
+   switch field {
+   case "bandwidth":
+      return t.item.Bandwidth
+   case "height":
+      return t.height
+   }
+   return t.width
+}
+
+func (t track) text(field string) string {
+   switch field {
+   case "codecs":
+      return t.item.Codecs
+   case "lang":
+      return t.item.Lang
+   case "name":
+      return t.item.Name
+   case "role":
+      return t.item.Role
+   }
+   return t.item.Type
+}
+
+// applies reports if r is about t. An unscoped rule skips the tracks that
+// lack the field.
+func (r rule) applies(t track) bool {
+   if r.kind != "" {
+      return r.kind == t.kind
+   }
+   if selector_fields[r.field] {
+      return t.number(r.field) >= 1
+   }
+   return t.text(r.field) != ""
+}
+
+// rank is the position of the first value that t matches, or -1
+func (r rule) rank(t track) int {
+   text := strings.ToLower(t.text(r.field))
+   for i, value := range r.values {
+      switch {
+      case value == "*":
+         return i
+      case r.field == "codecs", r.field == "lang":
+         if strings.HasPrefix(text, value) {
+            return i
+         }
+         // codecs is a list, such as avc1.64001f,mp4a.40.2
+         if strings.Contains(text, "," + value) {
+            return i
+         }
+      case text == value:
+         return i
+      }
+   }
+   return -1
+}
+
+func (r rule) distance(t track) int64 {
+   value := t.number(r.field) - r.number
+   if value < 0 {
+      return -value
+   }
+   return value
+}
+
+func (r rule) allow(t track) bool {
+   if !r.applies(t) {
+      return true
+   }
+   if !selector_fields[r.field] {
+      return r.rank(t) >= 0
+   }
+   value := t.number(r.field)
+   switch r.op {
+   case "<=":
+      return value <= r.number
+   case ">=":
+      return value >= r.number
+   case "<":
+      return value < r.number
+   case ">":
+      return value > r.number
+   case "=":
+      return value == r.number
+   }
+   return true
+}
+
+// better reports if a is preferred to b
+func (s Selector) better(a, b track) bool {
+   for _, r := range s.rules {
+      switch {
+      case r.op == "~":
+         da, db := r.distance(a), r.distance(b)
+         if !r.applies(a) || !r.applies(b) || da == db {
+            continue
+         }
+         return da < db
+      case !selector_fields[r.field]:
+         ra, rb := r.rank(a), r.rank(b)
+         if !r.applies(a) || !r.applies(b) || ra == rb {
+            continue
+         }
+         return ra < rb
+      }
+   }
+   return a.item.Bandwidth > b.item.Bandwidth
+}
+
+// Index returns the item that the rules choose, or index if the Selector is
+// unset
+func (s Selector) Index(items []Info_Item, index int) (int, error) {
+   if s.rules == nil {
+      return index, nil
+   }
+   best := -1
+   var tracks []track
+   for _, item := range items {
+      tracks = append(tracks, new_track(item))
+   }
+   for i, t := range tracks {
+      allow := true
+      for _, r := range s.rules {
+         if !r.allow(t) {
+            allow = false
+            break
+         }
+      }
+      if allow && (best == -1 || s.better(t, tracks[best])) {
+         best = i
+      }
+   }
+   if best == -1 {
+      return 0, errors.New("no track matches " + strconv.Quote(s.text))
+   }
+   return best, nil
+}
+
+// DASH_Index returns the representation that Select chooses, or index if
+// Select is unset
+func (s Stream) DASH_Index(items dash.Representations, index int) (int, error) {
+   var infos []Info_Item
+   for _, item := range items {
+      infos = append(infos, s.dash_item(item))
+   }
+   return s.Select.Index(infos, index)
+}
+
+// HLS_Streams_Index returns the variant that Select chooses, or index if
+// Select is unset
+func (s Stream) HLS_Streams_Index(items hls.Streams, index int) (int, error) {
+   var infos []Info_Item
+   for _, item := range items {
+      infos = append(infos, s.hls_item(item))
+   }
+   return s.Select.Index(infos, index)
+}
+
+// HLS_Media_Index returns the rendition that Select chooses, or index if
+// Select is unset
+func (s Stream) HLS_Media_Index(items hls.Media, index int) (int, error) {
+   var infos []Info_Item
+   for _, item := range items {
+      infos = append(infos, s.hls_item(item))
+   }
+   return s.Select.Index(infos, index)
+}
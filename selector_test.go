@@ -0,0 +1,59 @@
+package mech
+
+import (
+   "testing"
+)
+
+var selector_items = []Info_Item{
+   {Bandwidth: 9000000, Codecs: "avc1.640028", Mime_Type: "video/mp4", Resolution: "3840x2160"},
+   {Bandwidth: 6000000, Codecs: "hvc1.2.4.L123", Mime_Type: "video/mp4", Resolution: "1920x1080"},
+   {Bandwidth: 4000000, Codecs: "avc1.640028", Mime_Type: "video/mp4", Resolution: "1920x1080"},
+   {Bandwidth: 2000000, Codecs: "avc1.4d401f", Mime_Type: "video/mp4", Resolution: "1280x720"},
+   {Bandwidth: 128000, Codecs: "mp4a.40.2", Lang: "es", Mime_Type: "audio/mp4"},
+   {Bandwidth: 96000, Codecs: "mp4a.40.2", Lang: "en", Mime_Type: "audio/mp4"},
+}
+
+var selector_tests = []struct {
+   text string
+   kind string
+   want int // index among the items of kind
+}{
+   {"video.height<=1080,codecs=avc1|hvc1", "video", 2},
+   {"video.height<=1080,codecs=hvc1|avc1", "video", 1},
+   {"bandwidth<=3000000", "video", 3},
+   {"video.height~700", "video", 3},
+   {"audio.lang=en|*", "audio", 1},
+   {"audio.lang=fr|*", "audio", 0},
+}
+
+func Test_Selector(t *testing.T) {
+   for _, test := range selector_tests {
+      sel, err := New_Selector(test.text)
+      if err != nil {
+         t.Fatal(err)
+      }
+      var items []Info_Item
+      for _, item := range selector_items {
+         if new_track(item).kind == test.kind {
+            items = append(items, item)
+         }
+      }
+      index, err := sel.Index(items, -1)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if index != test.want {
+         t.Fatal(test, index)
+      }
+   }
+   if _, err := New_Selector("depth<=3"); err == nil {
+      t.Fatal("depth")
+   }
+   sel, err := New_Selector("audio.lang=fr")
+   if err != nil {
+      t.Fatal(err)
+   }
+   if _, err := sel.Index(selector_items[4:], 0); err == nil {
+      t.Fatal("fr")
+   }
+}
@@ -0,0 +1,445 @@
+package mech
+
+import (
+   "bytes"
+   "crypto/sha256"
+   "encoding/hex"
+   "errors"
+   "github.com/89z/mech/verify"
+   "io"
+   "mime"
+   "net/http"
+   "net/url"
+   "os"
+   "path"
+   "path/filepath"
+   "regexp"
+   "sort"
+   "strings"
+   "sync"
+   "time"
+)
+
+// Server re-streams a manifest that HLS or DASH can read, so that a player
+// that knows nothing of the site can play it. Every address in a manifest
+// is rewritten to a path on the server, as
+//
+//   /p/https/example.com/index.m3u8?query
+//
+// and what the player asks for is fetched with the client of Stream, with
+// its Retry. Only hosts from the manifests are fetched.
+type Server struct {
+   Cache string // directory of recent segments, empty for none
+   Cache_Size int64 // bytes kept in Cache
+   Header http.Header // sent with every request to the source
+   Stream Stream
+   hosts map[string]bool
+   mu sync.Mutex
+   ref *url.URL
+}
+
+func New_Server(str Stream, ref string) (*Server, error) {
+   addr, err := url.Parse(ref)
+   if err != nil {
+      return nil, err
+   }
+   if addr.Scheme != "http" && addr.Scheme != "https" {
+      return nil, errors.New("scheme " + addr.Scheme)
+   }
+   return &Server{
+      Cache_Size: 1 << 30,
+      Stream: str,
+      hosts: map[string]bool{addr.Host: true},
+      ref: addr,
+   }, nil
+}
+
+// Path is where the player finds the manifest
+func (s *Server) Path() string {
+   return s.local(s.ref)
+}
+
+// Listen serves on addr, such as :8080, until it fails
+func (s *Server) Listen(addr string) error {
+   os.Stderr.WriteString("Serve " + addr + s.Path() + "\n")
+   return http.ListenAndServe(addr, s)
+}
+
+// Serve re-streams the manifest at ref on addr, instead of downloading it.
+// Segments are kept in cache, unless it is empty.
+func (s Stream) Serve(ref, addr, cache string) error {
+   srv, err := New_Server(s, ref)
+   if err != nil {
+      return err
+   }
+   srv.Cache = cache
+   return srv.Listen(addr)
+}
+
+// local is the path of ref on the server, which can then be fetched
+func (s *Server) local(ref *url.URL) string {
+   s.mu.Lock()
+   s.hosts[ref.Host] = true
+   s.mu.Unlock()
+   var b strings.Builder
+   b.WriteString("/p/")
+   b.WriteString(ref.Scheme)
+   b.WriteByte('/')
+   b.WriteString(ref.Host)
+   b.WriteString(ref.EscapedPath())
+   if ref.RawQuery != "" {
+      b.WriteByte('?')
+      b.WriteString(ref.RawQuery)
+   }
+   return b.String()
+}
+
+// source is the reverse of local
+func (s *Server) source(req *http.Request) (*url.URL, error) {
+   rest := strings.TrimPrefix(req.URL.EscapedPath(), "/p/")
+   scheme, rest, ok := strings.Cut(rest, "/")
+   if !ok || scheme != "http" && scheme != "https" {
+      return nil, errors.New("path " + req.URL.Path)
+   }
+   host, rest, _ := strings.Cut(rest, "/")
+   s.mu.Lock()
+   known := s.hosts[host]
+   s.mu.Unlock()
+   if !known {
+      return nil, errors.New("host " + host)
+   }
+   return url.Parse(
+      scheme + "://" + host + "/" + rest + raw_query(req.URL.RawQuery),
+   )
+}
+
+func raw_query(raw string) string {
+   if raw == "" {
+      return ""
+   }
+   return "?" + raw
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+   if req.Method != "GET" && req.Method != "HEAD" {
+      http.Error(w, req.Method, http.StatusMethodNotAllowed)
+      return
+   }
+   if req.URL.Path == "/" {
+      http.Redirect(w, req, s.Path(), http.StatusFound)
+      return
+   }
+   ref, err := s.source(req)
+   if err != nil {
+      http.Error(w, err.Error(), http.StatusNotFound)
+      return
+   }
+   if manifest(ref.Path, "") {
+      var res *http.Response
+      res, err = s.get(req, ref)
+      if err == nil {
+         defer res.Body.Close()
+         err = s.manifest(w, req, ref, res)
+      }
+   } else {
+      err = s.segment(w, req, ref)
+   }
+   if err != nil {
+      http.Error(w, err.Error(), http.StatusBadGateway)
+   }
+}
+
+// manifest reports whether a response is a playlist or MPD, by extension or
+// by type
+func manifest(ref, content_type string) bool {
+   switch path.Ext(ref) {
+   case ".m3u8", ".mpd":
+      return true
+   }
+   content_type = strings.ToLower(content_type)
+   return strings.Contains(content_type, "mpegurl") ||
+      strings.Contains(content_type, "dash+xml")
+}
+
+func (s *Server) get(req *http.Request, ref *url.URL) (*http.Response, error) {
+   out, err := new_request(req.Context(), nil, ref.String())
+   if err != nil {
+      return nil, err
+   }
+   for key, values := range s.Header {
+      out.Header[key] = values
+   }
+   value := req.Header.Get("Range")
+   if value == "" {
+      return s.Stream.http_client().Redirect(nil).Do(out)
+   }
+   out.Header.Set("Range", value)
+   var whole whole_body
+   whole.RoundTripper = http.DefaultTransport
+   if s.Stream.Retry.Attempts >= 2 {
+      whole.RoundTripper = s.Stream.retry()
+   }
+   res, err := client.Transport(transport(&whole)).Redirect(nil).
+      Status(http.StatusPartialContent).Do(out)
+   if err != nil {
+      return nil, err
+   }
+   if whole.ok {
+      res.StatusCode = http.StatusOK
+   }
+   return res, nil
+}
+
+// whole_body lets the client, which takes one status, accept an origin
+// that ignores Range and sends the whole resource. The 200 is passed as a
+// 206, and ok is set so that it can be put back.
+type whole_body struct {
+   http.RoundTripper
+   ok bool
+}
+
+func (w *whole_body) RoundTrip(req *http.Request) (*http.Response, error) {
+   res, err := w.RoundTripper.RoundTrip(req)
+   if err != nil {
+      return nil, err
+   }
+   w.ok = res.StatusCode == http.StatusOK
+   if w.ok {
+      res.StatusCode = http.StatusPartialContent
+   }
+   return res, nil
+}
+
+// manifest is never cached, as live playlists change
+func (s *Server) manifest(
+   w http.ResponseWriter, req *http.Request, ref *url.URL, res *http.Response,
+) error {
+   body, err := io.ReadAll(res.Body)
+   if err != nil {
+      return err
+   }
+   base := res.Request.URL
+   if bytes.HasPrefix(body, []byte("#EXTM3U")) {
+      body, err = s.rewrite_HLS(base, body)
+      if err != nil {
+         return err
+      }
+      w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+   } else {
+      // relative addresses in an MPD go with the address of the MPD, so
+      // the player has to follow the redirect too
+      if base.String() != ref.String() {
+         http.Redirect(w, req, s.local(base), http.StatusFound)
+         return nil
+      }
+      body = s.rewrite_DASH(base, body)
+      w.Header().Set("Content-Type", "application/dash+xml")
+   }
+   w.Write(body)
+   return nil
+}
+
+var uri_attribute = regexp.MustCompile(`URI="[^"]*"`)
+
+// rewrite_HLS makes every address local, as the playlist may have come
+// from a redirect
+func (s *Server) rewrite_HLS(base *url.URL, body []byte) ([]byte, error) {
+   var (
+      err error
+      out []byte
+   )
+   local := func(ref string) string {
+      addr, parse_err := base.Parse(ref)
+      if parse_err != nil {
+         err = parse_err
+         return ref
+      }
+      return s.local(addr)
+   }
+   for _, line := range strings.SplitAfter(string(body), "\n") {
+      text := strings.TrimSpace(line)
+      switch {
+      case text == "":
+      case strings.HasPrefix(text, "#"):
+         line = uri_attribute.ReplaceAllStringFunc(line, func(attr string) string {
+            return `URI="` + local(attr[5:len(attr)-1]) + `"`
+         })
+      default:
+         line = strings.Replace(line, text, local(text), 1)
+      }
+      out = append(out, line...)
+   }
+   if err != nil {
+      return nil, err
+   }
+   return out, nil
+}
+
+var dash_address = regexp.MustCompile(
+   `(<BaseURL[^>]*>\s*|(?:initialization|media|sourceURL)=")` +
+   `(https?://[^"<\s]*|/[^"<\s]*)`,
+)
+
+// rewrite_DASH makes absolute addresses local. Relative ones already
+// resolve to the server.
+func (s *Server) rewrite_DASH(base *url.URL, body []byte) []byte {
+   return dash_address.ReplaceAllFunc(body, func(match []byte) []byte {
+      sub := dash_address.FindSubmatch(match)
+      addr, err := base.Parse(string(sub[2]))
+      if err != nil {
+         return match
+      }
+      local := append([]byte(nil), sub[1]...)
+      return append(local, s.local(addr)...)
+   })
+}
+
+// segment is served from Cache if it is there. A request for a range that
+// is not cached goes to the source, so that a large file is not fetched
+// whole.
+func (s *Server) segment(
+   w http.ResponseWriter, req *http.Request, ref *url.URL,
+) error {
+   name := s.cache_name(ref)
+   if name != "" {
+      if file, err := os.Open(name); err == nil {
+         defer file.Close()
+         now := time.Now()
+         os.Chtimes(name, now, now)
+         s.serve_cached(w, req, ref, file)
+         return nil
+      }
+   }
+   res, err := s.get(req, ref)
+   if err != nil {
+      return err
+   }
+   defer res.Body.Close()
+   if manifest(ref.Path, res.Header.Get("Content-Type")) {
+      return s.manifest(w, req, ref, res)
+   }
+   for _, key := range []string{
+      "Accept-Ranges", "Content-Length", "Content-Range", "Content-Type",
+   } {
+      if value := res.Header.Get(key); value != "" {
+         w.Header().Set(key, value)
+      }
+   }
+   w.WriteHeader(res.StatusCode)
+   if name == "" || res.StatusCode != http.StatusOK {
+      _, err := io.Copy(w, res.Body)
+      return err
+   }
+   // the segment goes to the player and the cache together, so it is never
+   // held in memory
+   temp, err := s.create_temp()
+   if err != nil {
+      os.Stderr.WriteString(err.Error() + "\n")
+      _, err := io.Copy(w, res.Body)
+      return err
+   }
+   defer os.Remove(temp.Name())
+   defer temp.Close()
+   spill := cache_writer{file: temp}
+   size, err := io.Copy(io.MultiWriter(w, &spill), res.Body)
+   if err != nil {
+      return err
+   }
+   err = verify.Length(ref.String(), res.ContentLength, size)
+   if err == nil {
+      err = spill.err
+   }
+   if err == nil {
+      err = temp.Close()
+   }
+   if err == nil {
+      err = s.store(temp.Name(), name)
+   }
+   if err != nil {
+      os.Stderr.WriteString(err.Error() + "\n")
+   }
+   return nil
+}
+
+// cache_writer drops what is left once a write fails, so that the cache
+// never cuts off a response
+type cache_writer struct {
+   err error
+   file *os.File
+}
+
+func (c *cache_writer) Write(p []byte) (int, error) {
+   if c.err == nil {
+      _, c.err = c.file.Write(p)
+   }
+   return len(p), nil
+}
+
+func (s *Server) serve_cached(
+   w http.ResponseWriter, req *http.Request, ref *url.URL, file *os.File,
+) {
+   if typ := mime.TypeByExtension(path.Ext(ref.Path)); typ != "" {
+      w.Header().Set("Content-Type", typ)
+   }
+   http.ServeContent(w, req, "", time.Time{}, file)
+}
+
+// cache_name is the file for ref in Cache, empty with no Cache
+func (s *Server) cache_name(ref *url.URL) string {
+   if s.Cache == "" {
+      return ""
+   }
+   sum := sha256.Sum256([]byte(ref.String()))
+   return filepath.Join(s.Cache, hex.EncodeToString(sum[:]))
+}
+
+// create_temp is a file in Cache for a segment that is still arriving
+func (s *Server) create_temp() (*os.File, error) {
+   if err := os.MkdirAll(s.Cache, os.ModePerm); err != nil {
+      return nil, err
+   }
+   return os.CreateTemp(s.Cache, "*.tmp")
+}
+
+// store renames temp to name, then removes the least recently used files
+// until Cache holds Cache_Size or less
+func (s *Server) store(temp, name string) error {
+   s.mu.Lock()
+   defer s.mu.Unlock()
+   if err := os.Rename(temp, name); err != nil {
+      return err
+   }
+   entries, err := os.ReadDir(s.Cache)
+   if err != nil {
+      return err
+   }
+   var (
+      files []os.FileInfo
+      size int64
+   )
+   for _, entry := range entries {
+      info, err := entry.Info()
+      if err != nil || !info.Mode().IsRegular() {
+         continue
+      }
+      // another segment that is still arriving
+      if strings.HasSuffix(info.Name(), ".tmp") {
+         continue
+      }
+      files = append(files, info)
+      size += info.Size()
+   }
+   sort.Slice(files, func(a, b int) bool {
+      return files[a].ModTime().Before(files[b].ModTime())
+   })
+   for _, info := range files {
+      if size <= s.Cache_Size {
+         break
+      }
+      if err := os.Remove(filepath.Join(s.Cache, info.Name())); err != nil {
+         return err
+      }
+      size -= info.Size()
+   }
+   return nil
+}
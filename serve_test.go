@@ -0,0 +1,150 @@
+package mech
+
+import (
+   "io"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "strings"
+   "testing"
+)
+
+func Test_Server(t *testing.T) {
+   var segments int
+   var source *httptest.Server
+   source = httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         switch r.URL.Path {
+         case "/master.m3u8":
+            w.Write([]byte("#EXTM3U\n" +
+               `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="a",URI="audio.m3u8"` + "\n" +
+               "#EXT-X-STREAM-INF:BANDWIDTH=1\n" +
+               source.URL + "/video/index.m3u8?token=1\n",
+            ))
+         case "/video/index.m3u8":
+            w.Write([]byte("#EXTM3U\n#EXTINF:4,\n0.ts\n#EXT-X-ENDLIST\n"))
+         case "/video/0.ts":
+            segments++
+            w.Write([]byte("segment"))
+         default:
+            http.NotFound(w, r)
+         }
+      },
+   ))
+   defer source.Close()
+   srv, err := New_Server(Stream{}, source.URL + "/master.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   srv.Cache = t.TempDir()
+   local := httptest.NewServer(srv)
+   defer local.Close()
+   get := func(ref string) string {
+      res, err := http.Get(local.URL + ref)
+      if err != nil {
+         t.Fatal(err)
+      }
+      defer res.Body.Close()
+      body, err := io.ReadAll(res.Body)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if res.StatusCode != http.StatusOK {
+         t.Fatal(res.Status, ref)
+      }
+      return string(body)
+   }
+   addr, err := url.Parse(source.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   prefix := "/p/http/" + addr.Host
+   master := get(srv.Path())
+   if !strings.Contains(master, `URI="` + prefix + `/audio.m3u8"`) {
+      t.Fatal(master)
+   }
+   video := prefix + "/video/index.m3u8?token=1"
+   if !strings.Contains(master, "\n" + video + "\n") {
+      t.Fatal(master)
+   }
+   if media := get(video); !strings.Contains(media, prefix + "/video/0.ts") {
+      t.Fatal(media)
+   }
+   for i := 0; i < 2; i++ {
+      if body := get(prefix + "/video/0.ts"); body != "segment" {
+         t.Fatal(body)
+      }
+   }
+   if segments != 1 {
+      t.Fatal(segments)
+   }
+   res, err := http.Get(local.URL + "/p/https/example.com/0.ts")
+   if err != nil {
+      t.Fatal(err)
+   }
+   res.Body.Close()
+   if res.StatusCode != http.StatusNotFound {
+      t.Fatal(res.Status)
+   }
+}
+
+func Test_Rewrite_DASH(t *testing.T) {
+   srv, err := New_Server(Stream{}, "http://example.com/dash/index.mpd")
+   if err != nil {
+      t.Fatal(err)
+   }
+   body := srv.rewrite_DASH(srv.ref, []byte(
+      `<BaseURL>https://cdn.example.com/a/</BaseURL>` +
+      `<SegmentTemplate media="/b/$Number%05d$.m4s" initialization="i.mp4"/>`,
+   ))
+   want := `<BaseURL>/p/https/cdn.example.com/a/</BaseURL>` +
+   `<SegmentTemplate media="/p/http/example.com/b/$Number%05d$.m4s" ` +
+   `initialization="i.mp4"/>`
+   if string(body) != want {
+      t.Fatal(string(body))
+   }
+}
+
+// an origin that ignores Range sends the whole segment, which is passed on
+func Test_Server_Range(t *testing.T) {
+   source := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         if r.Header.Get("Referer") != "https://example.com/" {
+            http.Error(w, "Referer", http.StatusForbidden)
+            return
+         }
+         w.Write([]byte("segment"))
+      },
+   ))
+   defer source.Close()
+   srv, err := New_Server(Stream{}, source.URL + "/index.m3u8")
+   if err != nil {
+      t.Fatal(err)
+   }
+   srv.Header = http.Header{"Referer": {"https://example.com/"}}
+   local := httptest.NewServer(srv)
+   defer local.Close()
+   addr, err := url.Parse(source.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   req, err := http.NewRequest(
+      "GET", local.URL + "/p/http/" + addr.Host + "/0.mp4", nil,
+   )
+   if err != nil {
+      t.Fatal(err)
+   }
+   req.Header.Set("Range", "bytes=0-2")
+   res, err := http.DefaultClient.Do(req)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer res.Body.Close()
+   body, err := io.ReadAll(res.Body)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if res.StatusCode != http.StatusOK || string(body) != "segment" {
+      t.Fatal(res.Status, string(body))
+   }
+}
@@ -0,0 +1,117 @@
+package sink
+
+import (
+   "archive/tar"
+   "bytes"
+   "github.com/89z/mech/meta"
+   "io"
+   "os"
+   "path/filepath"
+   "sync"
+   "time"
+)
+
+// Output is one download. Commit finishes it, and Close without Commit
+// abandons it.
+type Output interface {
+   io.Writer
+   Commit() error
+   Close() error
+}
+
+// Sink makes the outputs of downloads, by name
+type Sink interface {
+   Create(name string) (Output, error)
+}
+
+// File writes each output to a file of its name, through a meta.Part
+type File struct{}
+
+func (File) Create(name string) (Output, error) {
+   part, err := meta.Create_Part(name)
+   if err != nil {
+      return nil, err
+   }
+   return part, nil
+}
+
+// Writer writes every output to one io.Writer, one after another. Commit and
+// Close do nothing, so the io.Writer is left open.
+type Writer struct {
+   io.Writer
+}
+
+func New_Writer(w io.Writer) Writer {
+   return Writer{w}
+}
+
+// Stdout is for piping into a player
+var Stdout = New_Writer(os.Stdout)
+
+func (w Writer) Create(string) (Output, error) {
+   return writer_output{w.Writer}, nil
+}
+
+type writer_output struct {
+   io.Writer
+}
+
+func (writer_output) Commit() error {
+   return nil
+}
+
+func (writer_output) Close() error {
+   return nil
+}
+
+// Tar streams each output into one tar archive. The size of an entry comes
+// first, so each output is held in memory until Commit. Close writes the end
+// of the archive.
+type Tar struct {
+   mu sync.Mutex
+   w *tar.Writer
+}
+
+func New_Tar(w io.Writer) *Tar {
+   return &Tar{w: tar.NewWriter(w)}
+}
+
+func (t *Tar) Create(name string) (Output, error) {
+   return &tar_output{name: filepath.ToSlash(name), tar: t}, nil
+}
+
+func (t *Tar) Close() error {
+   t.mu.Lock()
+   defer t.mu.Unlock()
+   return t.w.Close()
+}
+
+type tar_output struct {
+   bytes.Buffer
+   name string
+   tar *Tar
+}
+
+func (t *tar_output) Commit() error {
+   t.tar.mu.Lock()
+   defer t.tar.mu.Unlock()
+   err := t.tar.w.WriteHeader(&tar.Header{
+      Mode: 0666,
+      ModTime: time.Now(),
+      Name: t.name,
+      Size: int64(t.Len()),
+      Typeflag: tar.TypeReg,
+   })
+   if err != nil {
+      return err
+   }
+   if _, err := t.WriteTo(t.tar.w); err != nil {
+      return err
+   }
+   return t.tar.w.Flush()
+}
+
+func (t *tar_output) Close() error {
+   t.Reset()
+   return nil
+}
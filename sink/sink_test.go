@@ -0,0 +1,78 @@
+package sink
+
+import (
+   "archive/tar"
+   "bytes"
+   "io"
+   "os"
+   "testing"
+)
+
+func Test_Tar(t *testing.T) {
+   var buf bytes.Buffer
+   arc := New_Tar(&buf)
+   for _, name := range []string{"video.m4v", "audio.m4a", "dropped.m4a"} {
+      out, err := arc.Create(name)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if _, err := io.WriteString(out, name); err != nil {
+         t.Fatal(err)
+      }
+      if name != "dropped.m4a" {
+         if err := out.Commit(); err != nil {
+            t.Fatal(err)
+         }
+      }
+      if err := out.Close(); err != nil {
+         t.Fatal(err)
+      }
+   }
+   if err := arc.Close(); err != nil {
+      t.Fatal(err)
+   }
+   read := tar.NewReader(&buf)
+   var names []string
+   for {
+      head, err := read.Next()
+      if err == io.EOF {
+         break
+      }
+      if err != nil {
+         t.Fatal(err)
+      }
+      body, err := io.ReadAll(read)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if string(body) != head.Name {
+         t.Fatal(string(body))
+      }
+      names = append(names, head.Name)
+   }
+   if len(names) != 2 || names[1] != "audio.m4a" {
+      t.Fatal(names)
+   }
+}
+
+func Test_File(t *testing.T) {
+   name := t.TempDir() + "/file.m4a"
+   out, err := File{}.Create(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer out.Close()
+   if _, err := io.WriteString(out, "file"); err != nil {
+      t.Fatal(err)
+   }
+   if err := out.Commit(); err != nil {
+      t.Fatal(err)
+   }
+   buf, err := os.ReadFile(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if string(buf) != "file" {
+      t.Fatal(string(buf))
+   }
+}
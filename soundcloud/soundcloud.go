@@ -1,11 +1,23 @@
 package soundcloud
 
 import (
+   "github.com/89z/mech/extractor"
    "github.com/89z/rosso/http"
    "net/url"
    "path"
 )
 
+// tracks and users both resolve, so the ID is the path
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "soundcloud",
+      Hosts: []string{"soundcloud.com"},
+      ID: func(ref *url.URL) (string, error) {
+         return ref.Path, nil
+      },
+   })
+}
+
 type Media struct {
    URL string // cf-media.sndcdn.com/QaV7QR1lxpc6.128.mp3
 }
@@ -22,6 +34,13 @@ const client_ID = "iZIs9mchVcX5lhVRyQGGAYlNPVldzAoX"
 
 var Client = http.Default_Client
 
+// Base_URL is the address of the v2 API
+type Base_URL struct {
+   API string
+}
+
+var Base = Base_URL{API: "https://api-v2.soundcloud.com"}
+
 type Image struct {
    Size string
    Crop bool
@@ -2,7 +2,9 @@ package soundcloud
 // github.com/89z
 
 import (
+   "context"
    "encoding/json"
+   "github.com/89z/mech/meta"
    "net/http"
    "net/url"
    "strconv"
@@ -13,6 +15,10 @@ import (
 // Also available is "hls", but all transcodings are quality "sq".
 // Same for "api-mobile.soundcloud.com".
 func (t Track) Progressive() (*Media, error) {
+   return t.Progressive_Context(context.Background())
+}
+
+func (t Track) Progressive_Context(ctx context.Context) (*Media, error) {
    var ref string
    for _, code := range t.Media.Transcodings {
       if code.Format.Protocol == "progressive" {
@@ -24,7 +30,7 @@ func (t Track) Progressive() (*Media, error) {
       return nil, err
    }
    req.URL.RawQuery = "client_id=" + client_ID
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -56,14 +62,19 @@ type Track struct {
 }
 
 func New_Track(id int64) (*Track, error) {
-   b := []byte("https://api-v2.soundcloud.com/tracks/")
+   return New_Track_Context(context.Background(), id)
+}
+
+func New_Track_Context(ctx context.Context, id int64) (*Track, error) {
+   b := []byte(Base.API)
+   b = append(b, "/tracks/"...)
    b = strconv.AppendInt(b, id, 10)
    req, err := http.NewRequest("GET", string(b), nil)
    if err != nil {
       return nil, err
    }
    req.URL.RawQuery = "client_id=" + client_ID
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -75,10 +86,18 @@ func New_Track(id int64) (*Track, error) {
    return tra, nil
 }
 
+// resolve is a track, or a user whose tracks are wanted
+type resolve struct {
+   Kind string
+   Track
+}
+
 func Resolve(ref string) ([]Track, error) {
-   req, err := http.NewRequest(
-      "GET", "https://api-v2.soundcloud.com/resolve", nil,
-   )
+   return Resolve_Context(context.Background(), ref)
+}
+
+func Resolve_Context(ctx context.Context, ref string) ([]Track, error) {
+   req, err := http.NewRequest("GET", Base.API + "/resolve", nil)
    if err != nil {
       return nil, err
    }
@@ -86,15 +105,12 @@ func Resolve(ref string) ([]Track, error) {
       "client_id": {client_ID},
       "url": {ref},
    }.Encode()
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
    defer res.Body.Close()
-   var solve struct {
-      Kind string
-      Track
-   }
+   var solve resolve
    if err := json.NewDecoder(res.Body).Decode(&solve); err != nil {
       return nil, err
    }
@@ -106,7 +122,12 @@ func Resolve(ref string) ([]Track, error) {
 
 // We can also paginate, but for now this is good enough.
 func User_Tracks(id int64) ([]Track, error) {
-   b := []byte("https://api-v2.soundcloud.com/users/")
+   return User_Tracks_Context(context.Background(), id)
+}
+
+func User_Tracks_Context(ctx context.Context, id int64) ([]Track, error) {
+   b := []byte(Base.API)
+   b = append(b, "/users/"...)
    b = strconv.AppendInt(b, id, 10)
    b = append(b, "/tracks"...)
    req, err := http.NewRequest("GET", string(b), nil)
@@ -117,7 +138,7 @@ func User_Tracks(id int64) ([]Track, error) {
       "client_id": {client_ID},
       "limit": {"999"},
    }.Encode()
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -143,6 +164,19 @@ func (t Track) Name() string {
    return t.User.Username + "-" + t.Title
 }
 
+func (t Track) Meta() meta.Data {
+   data := meta.Data{
+      Author: t.User.Username,
+      ID: strconv.FormatInt(t.ID, 10),
+      Site: "soundcloud",
+      Title: t.Title,
+   }
+   if len(t.Display_Date) >= 10 {
+      data.Date = t.Display_Date[:10]
+   }
+   return data
+}
+
 func (t Track) String() string {
    b := []byte("ID: ")
    b = strconv.AppendInt(b, t.ID, 10)
@@ -1,11 +1,20 @@
 package soundcloud
 
 import (
+   "encoding/json"
    "fmt"
+   "github.com/89z/mech/cassette"
+   "net/http"
+   "net/http/httptest"
+   "os"
    "testing"
    "time"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/soundcloud.json", &Client))
+}
+
 type item_type struct {
    id int64
    address string
@@ -17,6 +26,7 @@ var items = []item_type{
 }
 
 func Test_Resolve(t *testing.T) {
+   cassette.Require(t)
    for _, item := range items {
       tracks, err := Resolve(item.address)
       if err != nil {
@@ -30,6 +40,7 @@ func Test_Resolve(t *testing.T) {
 }
 
 func Test_Track(t *testing.T) {
+   cassette.Require(t)
    track, err := New_Track(items[0].id)
    if err != nil {
       t.Fatal(err)
@@ -40,3 +51,43 @@ func Test_Track(t *testing.T) {
    }
    fmt.Printf("%+v\n", media)
 }
+
+func Test_Base(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         switch r.URL.Path {
+         case "/resolve":
+            w.Write([]byte(`{"kind":"user","id":2}`))
+         case "/users/2/tracks":
+            w.Write([]byte(`{"collection":[{"id":1,"title":"local"}]}`))
+         default:
+            http.NotFound(w, r)
+         }
+      },
+   ))
+   defer server.Close()
+   base := Base
+   defer func() { Base = base }()
+   Base.API = server.URL
+   tracks, err := Resolve("https://soundcloud.com/kino-scmusic")
+   if err != nil {
+      t.Fatal(err)
+   }
+   if len(tracks) != 1 || tracks[0].Title != "local" {
+      t.Fatal(tracks)
+   }
+}
+
+func Test_Resolve_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/user.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var solve resolve
+   if err := json.Unmarshal(buf, &solve); err != nil {
+      t.Fatal(err)
+   }
+   if solve.Kind != "user" || solve.ID != 15713796 {
+      t.Fatal(solve.Kind, solve.ID)
+   }
+}
@@ -0,0 +1,278 @@
+package mech
+
+import (
+   "bytes"
+   "context"
+   "errors"
+   "github.com/89z/rosso/dash"
+   "github.com/edgeware/mp4ff/mp4"
+   "io"
+   "regexp"
+   "strings"
+   "time"
+)
+
+func text_ext(r dash.Representation) string {
+   switch r.MimeType {
+   case "text/vtt":
+      return ".vtt"
+   case "application/ttml+xml":
+      return ".ttml"
+   case "application/mp4":
+      switch {
+      case strings.HasPrefix(r.Codecs, "wvtt"):
+         return ".vtt"
+      case strings.HasPrefix(r.Codecs, "stpp"):
+         return ".ttml"
+      }
+   }
+   return ""
+}
+
+// Text returns the text representations: sidecar WebVTT or TTML, and
+// fragmented MP4 with wvtt or stpp samples.
+func Text(items dash.Representations) dash.Representations {
+   return items.Filter(func(r dash.Representation) bool {
+      return text_ext(r) != ""
+   })
+}
+
+// Text_Index returns the text representation that best matches lang and
+// role. An empty lang or role matches anything.
+func Text_Index(items dash.Representations, lang, role string) int {
+   score := func(r dash.Representation) int {
+      var s int
+      if lang == "" || strings.HasPrefix(r.Adaptation.Lang, lang) {
+         s += 2
+      }
+      if role == "" || r.Role() == role {
+         s++
+      }
+      return s
+   }
+   return items.Index(func(carry, item dash.Representation) bool {
+      return score(item) > score(carry)
+   })
+}
+
+// ttml joins TTML documents, keeping the head of the first and the body of
+// each
+type ttml struct {
+   bodies [][]byte
+   empty []byte
+   head []byte
+   tail []byte
+}
+
+var (
+   body_open = regexp.MustCompile(`<(\w+:)?body[^>]*>`)
+   body_close = regexp.MustCompile(`</(\w+:)?body>`)
+)
+
+func (t *ttml) add(doc []byte) {
+   open := body_open.FindIndex(doc)
+   end := body_close.FindIndex(doc)
+   if open == nil || end == nil {
+      if t.empty == nil {
+         t.empty = doc
+      }
+      return
+   }
+   if t.head == nil {
+      t.head = doc[:open[1]]
+      t.tail = doc[end[0]:]
+   }
+   t.bodies = append(t.bodies, doc[open[1]:end[0]])
+}
+
+func (t ttml) write(w io.Writer) error {
+   if t.head == nil {
+      _, err := w.Write(t.empty)
+      return err
+   }
+   parts := append([][]byte{t.head}, t.bodies...)
+   _, err := w.Write(bytes.Join(append(parts, t.tail), nil))
+   return err
+}
+
+// text_track unwraps the samples of fragmented wvtt or stpp
+type text_track struct {
+   scale uint32
+   sub *subtitle
+   trex *mp4.TrexBox
+   ttml *ttml
+}
+
+func (t *text_track) init(body []byte) error {
+   file, err := mp4.DecodeFile(bytes.NewReader(body))
+   if err != nil {
+      return err
+   }
+   if file.Init == nil || file.Init.Moov.Mvex == nil {
+      return errors.New("not fragmented MP4")
+   }
+   t.scale = file.Init.Moov.Trak.Mdia.Mdhd.Timescale
+   t.trex = file.Init.Moov.Mvex.Trex
+   return nil
+}
+
+func (t text_track) time(value uint64) time.Duration {
+   return time.Duration(value) * time.Second / time.Duration(t.scale)
+}
+
+func (t *text_track) segment(body []byte) error {
+   if t.scale == 0 {
+      return errors.New("no initialization segment")
+   }
+   file, err := mp4.DecodeFile(bytes.NewReader(body))
+   if err != nil {
+      return err
+   }
+   for _, seg := range file.Segments {
+      for _, frag := range seg.Fragments {
+         samples, err := frag.GetFullSamples(t.trex)
+         if err != nil {
+            return err
+         }
+         for _, sample := range samples {
+            if t.ttml != nil {
+               t.ttml.add(sample.Data)
+               continue
+            }
+            err := t.cues(sample.Data, sample.PresentationTime(), sample.Dur)
+            if err != nil {
+               return err
+            }
+         }
+      }
+   }
+   return nil
+}
+
+// cues reads the vttc boxes of a wvtt sample. vtte boxes are gaps with no
+// cue.
+func (t *text_track) cues(data []byte, start uint64, dur uint32) error {
+   read := bytes.NewReader(data)
+   var pos uint64
+   for read.Len() >= 1 {
+      box, err := mp4.DecodeBox(pos, read)
+      if err != nil {
+         return err
+      }
+      pos += box.Size()
+      vttc, ok := box.(*mp4.VttcBox)
+      if !ok || vttc.Payl == nil {
+         continue
+      }
+      item := cue{
+         start: t.time(start),
+         end: t.time(start + uint64(dur)),
+         text: vttc.Payl.CueText,
+      }
+      if vttc.Sttg != nil {
+         item.settings = vttc.Sttg.Settings
+      }
+      t.sub.add_cue(item)
+   }
+   return nil
+}
+
+func (s Stream) get(ctx context.Context, ref string) ([]byte, error) {
+   return get_segment(ctx, s.http_client(), s.base, segment{ref: ref})
+}
+
+// DASH_Text downloads a text representation as a standalone .vtt or .ttml
+// file. Fragmented wvtt and stpp are unwrapped from their MP4 boxes, and
+// WebVTT is written as SubRip if SRT is set.
+func (s Stream) DASH_Text(items dash.Representations, index int) error {
+   return s.DASH_Text_Context(context.Background(), items, index)
+}
+
+func (s Stream) DASH_Text_Context(
+   ctx context.Context, items dash.Representations, index int,
+) error {
+   if s.Info {
+      return s.DASH_Get_Context(ctx, items, index)
+   }
+   item := items[index]
+   ext := text_ext(item)
+   if ext == "" {
+      return errors.New("not a text representation " + item.ID)
+   }
+   var (
+      sub = new_subtitle()
+      track = text_track{sub: sub}
+      write func(int, []byte) error
+   )
+   switch {
+   case item.MimeType == "application/mp4":
+      if ext == ".ttml" {
+         track.ttml = new(ttml)
+      }
+      write = func(_ int, body []byte) error {
+         return track.segment(body)
+      }
+   case ext == ".ttml":
+      track.ttml = new(ttml)
+      write = func(_ int, body []byte) error {
+         track.ttml.add(body)
+         return nil
+      }
+   default:
+      write = func(_ int, body []byte) error {
+         return sub.add(body)
+      }
+   }
+   if item.SegmentTemplate == nil {
+      ref := s.mpd[item.ID].BaseURL
+      if ref == "" {
+         return errors.New("no BaseURL for " + item.ID)
+      }
+      body, err := s.get(ctx, ref)
+      if err != nil {
+         return err
+      }
+      if err := write(0, body); err != nil {
+         return err
+      }
+   } else {
+      if ref := item.Initialization(); ref != "" {
+         body, err := s.get(ctx, ref)
+         if err != nil {
+            return err
+         }
+         if item.MimeType == "application/mp4" {
+            err = track.init(body)
+         } else {
+            err = write(-1, body)
+         }
+         if err != nil {
+            return err
+         }
+      }
+      err := s.fetch(ctx, s.base, whole(item.Media()), 0, write)
+      if err != nil {
+         return err
+      }
+   }
+   if ext == ".vtt" && s.SRT {
+      ext = ".srt"
+   }
+   file, err := s.sink().Create(s.path(ext))
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   switch {
+   case track.ttml != nil:
+      err = track.ttml.write(file)
+   case s.SRT:
+      err = sub.write_srt(file)
+   default:
+      err = sub.write_vtt(file)
+   }
+   if err != nil {
+      return err
+   }
+   return file.Commit()
+}
@@ -0,0 +1,159 @@
+package mech
+
+import (
+   "bytes"
+   "github.com/edgeware/mp4ff/mp4"
+   "net/http"
+   "net/http/httptest"
+   "os"
+   "strconv"
+   "testing"
+)
+
+const text_mpd = `<MPD>
+<Period>
+   <AdaptationSet mimeType="audio/mp4" lang="en">
+      <Representation id="audio" bandwidth="128000" codecs="mp4a.40.2"/>
+   </AdaptationSet>
+   <AdaptationSet mimeType="text/vtt" lang="en">
+      <Representation id="sidecar" bandwidth="256">
+         <BaseURL>sidecar.vtt</BaseURL>
+      </Representation>
+   </AdaptationSet>
+   <AdaptationSet contentType="text" lang="es">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="subtitle"/>
+      <Representation id="wvtt" bandwidth="1000" codecs="wvtt" mimeType="application/mp4">
+         <SegmentTemplate timescale="1000" initialization="init.mp4" media="$Number$.m4s" startNumber="1">
+            <SegmentTimeline>
+               <S t="0" d="2000" r="1"/>
+            </SegmentTimeline>
+         </SegmentTemplate>
+      </Representation>
+   </AdaptationSet>
+</Period>
+</MPD>
+`
+
+const sidecar_vtt = `WEBVTT
+
+00:00:01.000 --> 00:00:02.000
+hello
+`
+
+// wvtt_sample is a vttc box, or a vtte box if text is empty
+func wvtt_sample(text string) ([]byte, error) {
+   var (
+      box mp4.Box = &mp4.VtteBox{}
+      buf bytes.Buffer
+   )
+   if text != "" {
+      vttc := new(mp4.VttcBox)
+      vttc.AddChild(&mp4.SttgBox{Settings: "line:0"})
+      vttc.AddChild(&mp4.PaylBox{CueText: text})
+      box = vttc
+   }
+   if err := box.Encode(&buf); err != nil {
+      return nil, err
+   }
+   return buf.Bytes(), nil
+}
+
+// the second cue runs across both segments
+func wvtt_segments() (map[string][]byte, error) {
+   segs := make(map[string][]byte)
+   var buf bytes.Buffer
+   init := mp4.CreateEmptyInit()
+   init.AddEmptyTrack(1000, "wvtt", "es")
+   if err := init.Encode(&buf); err != nil {
+      return nil, err
+   }
+   segs["/init.mp4"] = buf.Bytes()
+   type sample struct {
+      text string
+      time uint64
+      dur uint32
+   }
+   samples := [][]sample{
+      {{"", 0, 500}, {"uno", 500, 500}, {"dos", 1000, 500}},
+      {{"dos", 1500, 1500}, {"", 3000, 500}},
+   }
+   for i, seg := range samples {
+      frag, err := mp4.CreateFragment(uint32(i + 1), 1)
+      if err != nil {
+         return nil, err
+      }
+      for _, item := range seg {
+         data, err := wvtt_sample(item.text)
+         if err != nil {
+            return nil, err
+         }
+         frag.AddFullSample(mp4.FullSample{
+            Sample: mp4.Sample{Dur: item.dur, Size: uint32(len(data))},
+            DecodeTime: item.time,
+            Data: data,
+         })
+      }
+      var buf bytes.Buffer
+      if err := frag.Encode(&buf); err != nil {
+         return nil, err
+      }
+      segs["/" + strconv.Itoa(i + 1) + ".m4s"] = buf.Bytes()
+   }
+   return segs, nil
+}
+
+const wvtt_want = `WEBVTT
+
+00:00:00.500 --> 00:00:01.000 line:0
+uno
+
+00:00:01.000 --> 00:00:03.000 line:0
+dos
+`
+
+func Test_Text(t *testing.T) {
+   segs, err := wvtt_segments()
+   if err != nil {
+      t.Fatal(err)
+   }
+   segs["/manifest.mpd"] = []byte(text_mpd)
+   segs["/sidecar.vtt"] = []byte(sidecar_vtt)
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         w.Write(segs[r.URL.Path])
+      },
+   ))
+   defer server.Close()
+   chdir(t)
+   var str Stream
+   reps, err := str.DASH(server.URL + "/manifest.mpd")
+   if err != nil {
+      t.Fatal(err)
+   }
+   text := Text(reps)
+   if len(text) != 2 {
+      t.Fatal(text)
+   }
+   for _, test := range []struct{
+      lang, name, want string
+   }{
+      {"en", "sidecar", sidecar_vtt},
+      {"es", "wvtt", wvtt_want},
+   } {
+      index := Text_Index(text, test.lang, "subtitle")
+      if text[index].ID != test.name {
+         t.Fatal(text[index])
+      }
+      str.Name = test.name
+      if err := str.DASH_Text(text, index); err != nil {
+         t.Fatal(err)
+      }
+      got, err := os.ReadFile(test.name + ".vtt")
+      if err != nil {
+         t.Fatal(err)
+      }
+      if string(got) != test.want {
+         t.Fatal(string(got))
+      }
+   }
+}
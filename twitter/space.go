@@ -1,7 +1,11 @@
 package twitter
 
 import (
+   "context"
    "encoding/json"
+   "errors"
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/meta"
    "github.com/89z/rosso/http"
    "net/url"
    "path"
@@ -9,25 +13,50 @@ import (
    "time"
 )
 
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "twitter",
+      Hosts: []string{"twitter.com"},
+      ID: func(ref *url.URL) (string, error) {
+         if !strings.Contains(ref.Path, "/i/spaces/") {
+            return "", errors.New("twitter: not a space " + ref.String())
+         }
+         return SpaceID(ref.String())
+      },
+   })
+}
+
 const bearer =
    "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs=" +
    "1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
 
 var Client = http.Default_Client
 
+// Base_URL has the API host for guest tokens, and the site that has Spaces
+type Base_URL struct {
+   API string
+   Site string
+}
+
+var Base = Base_URL{API: "https://api.twitter.com", Site: "https://twitter.com"}
+
 type Guest struct {
    Guest_Token string
 }
 
 func New_Guest() (*Guest, error) {
+   return New_Guest_Context(context.Background())
+}
+
+func New_Guest_Context(ctx context.Context) (*Guest, error) {
    req, err := http.NewRequest(
-      "POST", "https://api.twitter.com/1.1/guest/activate.json", nil,
+      "POST", Base.API + "/1.1/guest/activate.json", nil,
    )
    if err != nil {
       return nil, err
    }
    req.Header.Set("Authorization", "Bearer " + bearer)
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -84,8 +113,15 @@ func (a Audio_Space) Time() time.Time {
 }
 
 func (g Guest) Audio_Space(id string) (*Audio_Space, error) {
+   return g.Audio_Space_Context(context.Background(), id)
+}
+
+func (g Guest) Audio_Space_Context(
+   ctx context.Context, id string,
+) (*Audio_Space, error) {
    var str strings.Builder
-   str.WriteString("https://twitter.com/i/api/graphql/")
+   str.WriteString(Base.Site)
+   str.WriteString("/i/api/graphql/")
    str.WriteString(spacePersistedQuery)
    str.WriteString("/AudioSpaceById")
    req, err := http.NewRequest("GET", str.String(), nil)
@@ -101,7 +137,7 @@ func (g Guest) Audio_Space(id string) (*Audio_Space, error) {
       return nil, err
    }
    req.URL.RawQuery = "variables=" + url.QueryEscape(string(buf))
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -118,8 +154,15 @@ func (g Guest) Audio_Space(id string) (*Audio_Space, error) {
 }
 
 func (g Guest) Source(space *Audio_Space) (*Source, error) {
+   return g.Source_Context(context.Background(), space)
+}
+
+func (g Guest) Source_Context(
+   ctx context.Context, space *Audio_Space,
+) (*Source, error) {
    var str strings.Builder
-   str.WriteString("https://twitter.com/i/api/1.1/live_video_stream/status/")
+   str.WriteString(Base.Site)
+   str.WriteString("/i/api/1.1/live_video_stream/status/")
    str.WriteString(space.Metadata.Media_Key)
    req, err := http.NewRequest("GET", str.String(), nil)
    if err != nil {
@@ -129,7 +172,7 @@ func (g Guest) Source(space *Audio_Space) (*Source, error) {
       "Authorization": {"Bearer " + bearer},
       "X-Guest-Token": {g.Guest_Token},
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -193,3 +236,17 @@ func (a Audio_Space) Base() string {
    buf.WriteString(a.Metadata.Title)
    return buf.String()
 }
+
+func (a Audio_Space) Meta() meta.Data {
+   data := meta.Data{
+      Date: a.Time().Format("2006-01-02"),
+      ID: a.Metadata.Media_Key,
+      Site: "twitter",
+      Title: a.Metadata.Title,
+   }
+   for _, admin := range a.Participants.Admins {
+      data.Author = admin.Display_Name
+      break
+   }
+   return data
+}
@@ -2,13 +2,22 @@ package twitter
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
+   "net/http"
+   "net/http/httptest"
+   "os"
    "testing"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/twitter.json", &Client))
+}
+
 // twitter.com/i/spaces/1jMJgLVmMlbxL
 const space_ID = "1jMJgLVmMlbxL"
 
 func Test_Space(t *testing.T) {
+   cassette.Require(t)
    g, err := New_Guest()
    if err != nil {
       t.Fatal(err)
@@ -20,3 +29,40 @@ func Test_Space(t *testing.T) {
    }
    fmt.Println(s)
 }
+
+func Test_Base(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         switch r.URL.Path {
+         case "/1.1/guest/activate.json":
+            w.Write([]byte(`{"guest_token":"1"}`))
+         case "/i/api/graphql/" + spacePersistedQuery + "/AudioSpaceById":
+            if r.Header.Get("X-Guest-Token") != "1" {
+               http.Error(w, "X-Guest-Token", http.StatusForbidden)
+               return
+            }
+            w.Write([]byte(
+               `{"data":{"audioSpace":{"metadata":{"title":"local"}}}}`,
+            ))
+         default:
+            http.NotFound(w, r)
+         }
+      },
+   ))
+   defer server.Close()
+   base := Base
+   defer func() { Base = base }()
+   Base.API = server.URL
+   Base.Site = server.URL
+   g, err := New_Guest()
+   if err != nil {
+      t.Fatal(err)
+   }
+   space, err := g.Audio_Space(space_ID)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if space.Metadata.Title != "local" {
+      t.Fatal(space.Metadata)
+   }
+}
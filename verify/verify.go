@@ -0,0 +1,343 @@
+package verify
+
+import (
+   "bufio"
+   "errors"
+   "github.com/edgeware/mp4ff/mp4"
+   "io"
+   "os"
+   "strconv"
+   "strings"
+   "time"
+)
+
+// Length_Error is a body that is not the length that was promised. It
+// matches io.ErrUnexpectedEOF, so a retry policy can treat it as transient.
+type Length_Error struct {
+   URL string
+   Want int64
+   Got int64
+}
+
+func (e Length_Error) Error() string {
+   b := []byte("length ")
+   b = strconv.AppendInt(b, e.Got, 10)
+   b = append(b, ", want "...)
+   b = strconv.AppendInt(b, e.Want, 10)
+   if e.URL != "" {
+      b = append(b, ' ')
+      b = append(b, e.URL...)
+   }
+   return string(b)
+}
+
+func (Length_Error) Unwrap() error {
+   return io.ErrUnexpectedEOF
+}
+
+// Length returns a Length_Error if got is not want. A negative want is
+// unknown, and always passes.
+func Length(ref string, want, got int64) error {
+   if want >= 0 && got != want {
+      return Length_Error{URL: ref, Want: want, Got: got}
+   }
+   return nil
+}
+
+// Duration_Error is a file whose media is not as long as its manifest says
+type Duration_Error struct {
+   Name string
+   Want time.Duration
+   Got time.Duration
+}
+
+func (e Duration_Error) Error() string {
+   var b strings.Builder
+   b.WriteString("duration ")
+   b.WriteString(e.Got.String())
+   b.WriteString(", want ")
+   b.WriteString(e.Want.String())
+   if e.Name != "" {
+      b.WriteByte(' ')
+      b.WriteString(e.Name)
+   }
+   return b.String()
+}
+
+// Tolerance is how far a duration can be from the manifest, as the last
+// sample or a priming delay is often not counted the same way
+var Tolerance = 2 * time.Second
+
+// Duration returns a Duration_Error if got and want are further apart than
+// Tolerance, or two percent of want if that is larger
+func Duration(name string, want, got time.Duration) error {
+   diff := got - want
+   if diff < 0 {
+      diff = -diff
+   }
+   limit := Tolerance
+   if want/50 > limit {
+      limit = want / 50
+   }
+   if diff > limit {
+      return Duration_Error{Name: name, Want: want, Got: got}
+   }
+   return nil
+}
+
+// File checks the duration of the named MP4, TS or ADTS file against want.
+// A want of zero is unknown, and always passes.
+func File(name string, want time.Duration) error {
+   if want <= 0 {
+      return nil
+   }
+   file, err := os.Open(name)
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   head := make([]byte, 3)
+   n, err := file.ReadAt(head, 0)
+   if n == 0 {
+      return err
+   }
+   head = head[:n]
+   var got time.Duration
+   switch {
+   case head[0] == ts_sync:
+      got, err = TS(bufio.NewReader(file))
+   case string(head) == "ID3", adts_sync(head):
+      got, err = ADTS(bufio.NewReader(file))
+   default:
+      got, err = MP4(file)
+   }
+   if err != nil {
+      return err
+   }
+   return Duration(name, want, got)
+}
+
+// MP4 returns the longest track of a fragmented or plain MP4
+func MP4(r io.ReadSeeker) (time.Duration, error) {
+   file, err := mp4.DecodeFile(r, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+   if err != nil {
+      return 0, err
+   }
+   moov := file.Moov
+   if moov == nil {
+      return 0, errors.New("moov not found")
+   }
+   scales := make(map[uint32]uint32)
+   for _, trak := range moov.Traks {
+      scales[trak.Tkhd.TrackID] = trak.Mdia.Mdhd.Timescale
+   }
+   trexs := make(map[uint32]*mp4.TrexBox)
+   if moov.Mvex != nil {
+      for _, trex := range moov.Mvex.Trexs {
+         trexs[trex.TrackID] = trex
+      }
+   }
+   durs := make(map[uint32]uint64)
+   for _, seg := range file.Segments {
+      for _, frag := range seg.Fragments {
+         for _, traf := range frag.Moof.Trafs {
+            id := traf.Tfhd.TrackID
+            durs[id] += Traf_Duration(traf, trexs[id])
+         }
+      }
+   }
+   if len(durs) == 0 {
+      if moov.Mvhd == nil || moov.Mvhd.Timescale == 0 {
+         return 0, errors.New("duration not found")
+      }
+      return scale(moov.Mvhd.Duration, moov.Mvhd.Timescale), nil
+   }
+   var long time.Duration
+   for id, dur := range durs {
+      if scales[id] == 0 {
+         return 0, errors.New("timescale not found")
+      }
+      if dur := scale(dur, scales[id]); dur > long {
+         long = dur
+      }
+   }
+   return long, nil
+}
+
+// Traf_Duration is the sum of the sample durations of traf. Samples without
+// a duration take it from tfhd, or else trex, which can be nil.
+func Traf_Duration(traf *mp4.TrafBox, trex *mp4.TrexBox) uint64 {
+   var def uint32
+   if traf.Tfhd.HasDefaultSampleDuration() {
+      def = traf.Tfhd.DefaultSampleDuration
+   } else if trex != nil {
+      def = trex.DefaultSampleDuration
+   }
+   var dur uint64
+   for _, trun := range traf.Truns {
+      for _, sample := range trun.Samples {
+         if trun.HasSampleDuration() {
+            dur += uint64(sample.Dur)
+         } else {
+            dur += uint64(def)
+         }
+      }
+   }
+   return dur
+}
+
+func scale(dur uint64, timescale uint32) time.Duration {
+   sec := dur / uint64(timescale)
+   rem := dur % uint64(timescale)
+   return time.Duration(sec) * time.Second +
+      time.Duration(rem) * time.Second / time.Duration(timescale)
+}
+
+const (
+   ts_packet = 188
+   ts_sync = 'G'
+)
+
+// TS returns the longest span of presentation time of any stream. The last
+// frame is not counted, which Tolerance covers.
+func TS(r io.Reader) (time.Duration, error) {
+   type span struct {
+      first, last uint64
+   }
+   spans := make(map[uint16]*span)
+   packet := make([]byte, ts_packet)
+   for {
+      _, err := io.ReadFull(r, packet)
+      if err == io.EOF {
+         break
+      }
+      if err != nil {
+         return 0, err
+      }
+      if packet[0] != ts_sync {
+         return 0, errors.New("TS sync byte not found")
+      }
+      pts, ok := ts_pts(packet)
+      if !ok {
+         continue
+      }
+      pid := uint16(packet[1] & 0x1F) << 8 | uint16(packet[2])
+      s, ok := spans[pid]
+      if !ok {
+         spans[pid] = &span{first: pts, last: pts}
+         continue
+      }
+      if pts < s.last && s.last - pts > 1 << 32 {
+         pts += 1 << 33 // wrapped
+      }
+      if pts > s.last {
+         s.last = pts
+      }
+   }
+   if len(spans) == 0 {
+      return 0, errors.New("PTS not found")
+   }
+   var long uint64
+   for _, s := range spans {
+      if s.last - s.first > long {
+         long = s.last - s.first
+      }
+   }
+   return scale(long, 90_000), nil
+}
+
+// ts_pts returns the PTS of a packet that starts a PES
+func ts_pts(packet []byte) (uint64, bool) {
+   if packet[1] & 0x40 == 0 {
+      return 0, false
+   }
+   pes := packet[4:]
+   switch packet[3] >> 4 & 3 {
+   case 1:
+   case 3:
+      if int(pes[0]) + 1 > len(pes) {
+         return 0, false
+      }
+      pes = pes[pes[0] + 1:]
+   default:
+      return 0, false
+   }
+   if len(pes) < 14 || pes[0] != 0 || pes[1] != 0 || pes[2] != 1 {
+      return 0, false
+   }
+   if pes[7] & 0x80 == 0 {
+      return 0, false
+   }
+   b := pes[9:14]
+   pts := uint64(b[0] >> 1 & 7) << 30
+   pts |= uint64(b[1]) << 22
+   pts |= uint64(b[2] >> 1) << 15
+   pts |= uint64(b[3]) << 7
+   pts |= uint64(b[4] >> 1)
+   return pts, true
+}
+
+// sampling frequencies of ADTS, by index
+var adts_rates = []int64{
+   96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000,
+   11025, 8000, 7350,
+}
+
+func adts_sync(head []byte) bool {
+   return len(head) >= 2 && head[0] == 0xFF && head[1] & 0xF0 == 0xF0
+}
+
+// ADTS returns the length of AAC in ADTS frames, as HLS audio renditions
+// are. The ID3 tags that come before each segment are skipped.
+func ADTS(r io.Reader) (time.Duration, error) {
+   var (
+      dur time.Duration
+      head = make([]byte, 10)
+   )
+   for {
+      _, err := io.ReadFull(r, head[:7])
+      if err == io.EOF {
+         break
+      }
+      if err != nil {
+         return 0, err
+      }
+      if string(head[:3]) == "ID3" {
+         if _, err := io.ReadFull(r, head[7:10]); err != nil {
+            return 0, err
+         }
+         // syncsafe size, then a footer if the flag is set
+         size := int64(head[6]) << 21 | int64(head[7]) << 14 |
+            int64(head[8]) << 7 | int64(head[9])
+         if head[5] & 0x10 != 0 {
+            size += 10
+         }
+         if _, err := io.CopyN(io.Discard, r, size); err != nil {
+            return 0, err
+         }
+         continue
+      }
+      if !adts_sync(head) {
+         return 0, errors.New("ADTS sync word not found")
+      }
+      index := int(head[2] >> 2 & 0xF)
+      if index >= len(adts_rates) {
+         return 0, errors.New("ADTS sampling frequency")
+      }
+      length := int64(head[3] & 3) << 11 | int64(head[4]) << 3 |
+         int64(head[5] >> 5)
+      if length < 7 {
+         return 0, errors.New("ADTS frame length")
+      }
+      if _, err := io.CopyN(io.Discard, r, length - 7); err != nil {
+         return 0, err
+      }
+      samples := 1024 * int64(head[6] & 3 + 1)
+      dur += time.Duration(samples) * time.Second /
+         time.Duration(adts_rates[index])
+   }
+   if dur == 0 {
+      return 0, errors.New("ADTS frame not found")
+   }
+   return dur, nil
+}
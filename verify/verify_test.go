@@ -0,0 +1,138 @@
+package verify
+
+import (
+   "bytes"
+   "errors"
+   "github.com/edgeware/mp4ff/mp4"
+   "io"
+   "os"
+   "testing"
+   "time"
+)
+
+// packet that starts a PES with pts
+func ts_packet_pts(pid uint16, pts uint64) []byte {
+   packet := make([]byte, ts_packet)
+   packet[0] = ts_sync
+   packet[1] = 0x40 | byte(pid >> 8)
+   packet[2] = byte(pid)
+   packet[3] = 0x10
+   pes := packet[4:]
+   copy(pes, []byte{0, 0, 1, 0xE0, 0, 0, 0x80, 0x80, 5})
+   pes[9] = 0x21 | byte(pts >> 29 & 0x0E)
+   pes[10] = byte(pts >> 22)
+   pes[11] = byte(pts >> 14) | 1
+   pes[12] = byte(pts >> 7)
+   pes[13] = byte(pts << 1) | 1
+   return packet
+}
+
+func Test_TS(t *testing.T) {
+   var buf bytes.Buffer
+   for i := uint64(0); i < 5; i++ {
+      buf.Write(ts_packet_pts(256, 900_000 + i * 90_000))
+      buf.Write(ts_packet_pts(257, 900_000 + i * 45_000))
+   }
+   dur, err := TS(&buf)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if dur != 4 * time.Second {
+      t.Fatal(dur)
+   }
+}
+
+func Test_MP4(t *testing.T) {
+   name := t.TempDir() + "/verify.m4a"
+   file, err := os.Create(name)
+   if err != nil {
+      t.Fatal(err)
+   }
+   defer file.Close()
+   init := mp4.CreateEmptyInit()
+   init.AddEmptyTrack(48000, "audio", "und")
+   if err := init.Encode(file); err != nil {
+      t.Fatal(err)
+   }
+   for i := 0; i < 3; i++ {
+      frag, err := mp4.CreateFragment(uint32(i + 1), 1)
+      if err != nil {
+         t.Fatal(err)
+      }
+      frag.AddFullSample(mp4.FullSample{
+         Sample: mp4.Sample{Dur: 48000, Size: 1},
+         DecodeTime: uint64(i) * 48000,
+         Data: []byte{byte(i)},
+      })
+      if err := frag.Encode(file); err != nil {
+         t.Fatal(err)
+      }
+   }
+   if _, err := file.Seek(0, io.SeekStart); err != nil {
+      t.Fatal(err)
+   }
+   dur, err := MP4(file)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if dur != 3 * time.Second {
+      t.Fatal(dur)
+   }
+   if err := File(name, 4 * time.Second); err != nil {
+      t.Fatal(err)
+   }
+   var dur_err Duration_Error
+   if !errors.As(File(name, 9 * time.Second), &dur_err) {
+      t.Fatal(dur_err)
+   }
+}
+
+func Test_Length(t *testing.T) {
+   if err := Length("", -1, 9); err != nil {
+      t.Fatal(err)
+   }
+   err := Length("", 9, 5)
+   if !errors.Is(err, io.ErrUnexpectedEOF) {
+      t.Fatal(err)
+   }
+}
+
+// frame of AAC at 16 kHz, with length bytes in all
+func adts_frame(length int) []byte {
+   frame := make([]byte, length)
+   frame[0] = 0xFF
+   frame[1] = 0xF1
+   frame[2] = 0x40 | 8 << 2
+   frame[3] = byte(length >> 11 & 3)
+   frame[4] = byte(length >> 3)
+   frame[5] = byte(length << 5) | 0x1F
+   frame[6] = 0xFC
+   return frame
+}
+
+func Test_ADTS(t *testing.T) {
+   var buf bytes.Buffer
+   for segment := 0; segment < 2; segment++ {
+      // ID3 tag with 5 bytes of frames
+      buf.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 5})
+      buf.Write(make([]byte, 5))
+      for i := 0; i < 25; i++ {
+         buf.Write(adts_frame(9 + i))
+      }
+   }
+   name := t.TempDir() + "/audio.aac"
+   if err := os.WriteFile(name, buf.Bytes(), 0666); err != nil {
+      t.Fatal(err)
+   }
+   dur, err := ADTS(bytes.NewReader(buf.Bytes()))
+   if err != nil {
+      t.Fatal(err)
+   }
+   // 50 frames of 1024 samples
+   if dur != 3200 * time.Millisecond {
+      t.Fatal(dur)
+   }
+   if err := File(name, 3200 * time.Millisecond); err != nil {
+      t.Fatal(err)
+   }
+}
@@ -1,6 +1,9 @@
 package vimeo
 
 import (
+   "context"
+   "github.com/89z/mech/meta"
+   "github.com/89z/rosso/http"
    "github.com/89z/rosso/json"
    "io"
    "strconv"
@@ -34,6 +37,18 @@ func (c Config) String() string {
    return string(b)
 }
 
+func (c Config) Meta() meta.Data {
+   data := meta.Data{
+      ID: strconv.FormatInt(c.Video.ID, 10),
+      Site: "vimeo",
+      Title: c.Video.Title,
+   }
+   if len(c.SEO.Upload_Date) >= 10 {
+      data.Date = c.SEO.Upload_Date[:10]
+   }
+   return data
+}
+
 func (c Config) Duration() time.Duration {
    return time.Duration(c.Video.Duration) * time.Second
 }
@@ -61,7 +76,15 @@ type Config struct {
 }
 
 func (e Embed) Config() (*Config, error) {
-   res, err := Client.Get(e.Config_URL)
+   return e.Config_Context(context.Background())
+}
+
+func (e Embed) Config_Context(ctx context.Context) (*Config, error) {
+   req, err := http.NewRequest("GET", e.Config_URL, nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -78,7 +101,15 @@ type Embed struct {
 }
 
 func New_Embed(ref string) (*Embed, error) {
-   res, err := Client.Get(ref)
+   return New_Embed_Context(context.Background(), ref)
+}
+
+func New_Embed_Context(ctx context.Context, ref string) (*Embed, error) {
+   req, err := http.NewRequest("GET", ref, nil)
+   if err != nil {
+      return nil, err
+   }
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
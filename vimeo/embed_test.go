@@ -1,7 +1,11 @@
 package vimeo
 
 import (
+   "encoding/json"
    "fmt"
+   "github.com/89z/mech/cassette"
+   "os"
+   "strings"
    "testing"
    "time"
 )
@@ -12,6 +16,7 @@ var embed_refs = []string{
 }
 
 func Test_Embed(t *testing.T) {
+   cassette.Require(t)
    for i := 0; i < 9; i++ {
       for _, ref := range embed_refs {
          emb, err := New_Embed(ref)
@@ -27,3 +32,36 @@ func Test_Embed(t *testing.T) {
       }
    }
 }
+
+func Test_Embed_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/vhx-17901.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var emb Embed
+   if err := json.Unmarshal(buf, &emb); err != nil {
+      t.Fatal(err)
+   }
+   if !strings.HasPrefix(
+      emb.Config_URL, "https://player.vimeo.com/video/322402891/config?",
+   ) {
+      t.Fatal(emb.Config_URL)
+   }
+}
+
+func Test_Config_Fixture(t *testing.T) {
+   buf, err := os.ReadFile("testdata/vimeo-322402891.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   var con Config
+   if err := json.Unmarshal(buf, &con); err != nil {
+      t.Fatal(err)
+   }
+   if con.Video.ID != 322402891 || con.Video.Duration != 148 {
+      t.Fatal(con.Video)
+   }
+   if len(con.Request.Files.Progressive) != 4 {
+      t.Fatal(con.Request.Files.Progressive)
+   }
+}
@@ -1,7 +1,10 @@
 package vimeo
 
 import (
+   "context"
    "encoding/json"
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/meta"
    "github.com/89z/rosso/http"
    "net/url"
    "strconv"
@@ -9,6 +12,20 @@ import (
    "time"
 )
 
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "vimeo",
+      Hosts: []string{"vimeo.com"},
+      ID: func(ref *url.URL) (string, error) {
+         clip, err := New_Clip(ref.String())
+         if err != nil {
+            return "", err
+         }
+         return strconv.FormatInt(clip.ID, 10), nil
+      },
+   })
+}
+
 func (v Video) Get_Duration() time.Duration {
    return time.Duration(v.Duration) * time.Second
 }
@@ -54,6 +71,14 @@ func (v Video) String() string {
    return string(b)
 }
 
+func (v Video) Meta() meta.Data {
+   data := meta.Data{Author: v.User.Name, Site: "vimeo", Title: v.Name}
+   if len(v.Release_Time) >= 10 {
+      data.Date = v.Release_Time[:10]
+   }
+   return data
+}
+
 type Clip struct {
    ID int64
    Unlisted_Hash string
@@ -88,12 +113,16 @@ func New_Clip(reference string) (*Clip, error) {
 }
 
 func New_JSON_Web() (*JSON_Web, error) {
-   req, err := http.NewRequest("GET", "https://vimeo.com/_next/jwt", nil)
+   return New_JSON_Web_Context(context.Background())
+}
+
+func New_JSON_Web_Context(ctx context.Context) (*JSON_Web, error) {
+   req, err := http.NewRequest("GET", Base.Site + "/_next/jwt", nil)
    if err != nil {
       return nil, err
    }
    req.Header.Set("X-Requested-With", "XMLHttpRequest")
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -107,12 +136,27 @@ func New_JSON_Web() (*JSON_Web, error) {
 
 var Client = http.Default_Client
 
+// Base_URL has the API and web origins of Vimeo
+type Base_URL struct {
+   API string
+   Site string // for the JSON web token
+}
+
+var Base = Base_URL{API: "https://api.vimeo.com", Site: "https://vimeo.com"}
+
 type JSON_Web struct {
    Token string
 }
 
 func (w JSON_Web) Video(clip *Clip) (*Video, error) {
-   b := []byte("https://api.vimeo.com/videos/")
+   return w.Video_Context(context.Background(), clip)
+}
+
+func (w JSON_Web) Video_Context(
+   ctx context.Context, clip *Clip,
+) (*Video, error) {
+   b := []byte(Base.API)
+   b = append(b, "/videos/"...)
    b = strconv.AppendInt(b, clip.ID, 10)
    if clip.Unlisted_Hash != "" {
       b = append(b, ':')
@@ -124,7 +168,7 @@ func (w JSON_Web) Video(clip *Clip) (*Video, error) {
    }
    req.Header.Set("Authorization", "JWT " + w.Token)
    req.URL.RawQuery = "fields=duration,download,name,pictures,release_time,user"
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
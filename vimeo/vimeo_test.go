@@ -2,10 +2,16 @@ package vimeo
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
+   "os"
    "testing"
    "time"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/vimeo.json", &Client))
+}
+
 var clip_refs = []string{
    "https://vimeo.com/477957994/2282452868",
    "https://player.vimeo.com/video/412573977?h=f7f2d6fcb7",
@@ -15,6 +21,7 @@ var clip_refs = []string{
 }
 
 func Test_Vimeo(t *testing.T) {
+   cassette.Require(t)
    for _, ref := range clip_refs {
       clip, err := New_Clip(ref)
       if err != nil {
@@ -30,6 +37,7 @@ var clips = []Clip{
 }
 
 func Test_Clip(t *testing.T) {
+   cassette.Require(t)
    web, err := New_JSON_Web()
    if err != nil {
       t.Fatal(err)
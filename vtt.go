@@ -0,0 +1,347 @@
+package mech
+
+import (
+   "bufio"
+   "bytes"
+   "context"
+   "errors"
+   "github.com/89z/rosso/hls"
+   "html"
+   "io"
+   "sort"
+   "strconv"
+   "strings"
+   "time"
+)
+
+// MPEG-TS clock rate, and the point where its 33 bit counter rolls over
+const (
+   mpegts_rate = 90_000
+   mpegts_wrap = 1 << 33
+)
+
+// parse_timestamp reads a WebVTT timestamp such as 01:02:03.456 or
+// 02:03.456
+func parse_timestamp(s string) (time.Duration, error) {
+   fields := strings.Split(strings.TrimSpace(s), ":")
+   if len(fields) < 2 || len(fields) > 3 {
+      return 0, errors.New("timestamp " + strconv.Quote(s))
+   }
+   var minutes int64
+   for _, field := range fields[:len(fields)-1] {
+      value, err := strconv.ParseInt(field, 10, 64)
+      if err != nil {
+         return 0, err
+      }
+      minutes = minutes * 60 + value
+   }
+   stamp := time.Duration(minutes) * time.Minute
+   sec, milli, _ := strings.Cut(fields[len(fields)-1], ".")
+   value, err := strconv.ParseInt(sec, 10, 64)
+   if err != nil {
+      return 0, err
+   }
+   stamp += time.Duration(value) * time.Second
+   if milli != "" {
+      value, err := strconv.ParseInt(milli, 10, 64)
+      if err != nil {
+         return 0, err
+      }
+      stamp += time.Duration(value) * time.Millisecond
+   }
+   return stamp, nil
+}
+
+// format_timestamp writes hh:mm:ss.ttt, with sep before the milliseconds
+func format_timestamp(d time.Duration, sep byte) string {
+   if d < 0 {
+      d = 0
+   }
+   milli := d.Milliseconds()
+   b := []byte("00:00:00.000")
+   put := func(end int, value int64, size int) {
+      for i := 0; i < size; i++ {
+         b[end-i] = byte('0' + value % 10)
+         value /= 10
+      }
+   }
+   put(11, milli % 1000, 3)
+   put(7, milli / 1000 % 60, 2)
+   put(4, milli / 60_000 % 60, 2)
+   put(1, milli / 3_600_000 % 100, 2)
+   b[8] = sep
+   return string(b)
+}
+
+type cue struct {
+   start time.Duration
+   end time.Duration
+   settings string
+   text string
+}
+
+// vtt_segment is one WebVTT segment of a subtitle playlist
+type vtt_segment struct {
+   cues []cue
+   local time.Duration
+   mpegts int64 // -1 without X-TIMESTAMP-MAP
+}
+
+func new_vtt_segment(body []byte) (*vtt_segment, error) {
+   seg := vtt_segment{mpegts: -1}
+   scan := bufio.NewScanner(bytes.NewReader(body))
+   var block []string
+   flush := func() error {
+      defer func() {
+         block = nil
+      }()
+      for i, line := range block {
+         if !strings.Contains(line, "-->") {
+            continue
+         }
+         start, end, _ := strings.Cut(line, "-->")
+         end = strings.TrimSpace(end)
+         end, settings, _ := strings.Cut(end, " ")
+         var (
+            item cue
+            err error
+         )
+         item.start, err = parse_timestamp(start)
+         if err != nil {
+            return err
+         }
+         item.end, err = parse_timestamp(end)
+         if err != nil {
+            return err
+         }
+         item.settings = strings.TrimSpace(settings)
+         item.text = strings.Join(block[i+1:], "\n")
+         seg.cues = append(seg.cues, item)
+         return nil
+      }
+      return nil
+   }
+   for scan.Scan() {
+      line := strings.TrimRight(scan.Text(), "\r")
+      line = strings.TrimPrefix(line, "\uFEFF")
+      switch {
+      case strings.HasPrefix(line, "WEBVTT"):
+      case strings.HasPrefix(line, "X-TIMESTAMP-MAP="):
+         line = strings.TrimPrefix(line, "X-TIMESTAMP-MAP=")
+         for _, field := range strings.Split(line, ",") {
+            key, value, _ := strings.Cut(field, ":")
+            var err error
+            switch key {
+            case "LOCAL":
+               seg.local, err = parse_timestamp(value)
+            case "MPEGTS":
+               seg.mpegts, err = strconv.ParseInt(value, 10, 64)
+            }
+            if err != nil {
+               return nil, err
+            }
+         }
+      case line == "":
+         if err := flush(); err != nil {
+            return nil, err
+         }
+      default:
+         block = append(block, line)
+      }
+   }
+   if err := scan.Err(); err != nil {
+      return nil, err
+   }
+   if err := flush(); err != nil {
+      return nil, err
+   }
+   return &seg, nil
+}
+
+// subtitle stitches WebVTT segments into one timeline. Cue times are moved
+// by each segment X-TIMESTAMP-MAP, relative to the first segment, and cues
+// repeated across segments are kept once.
+type subtitle struct {
+   base int64 // MPEGTS of the first mapped segment, -1 until then
+   cues []cue
+   seen map[cue]bool
+}
+
+func new_subtitle() *subtitle {
+   return &subtitle{base: -1, seen: make(map[cue]bool)}
+}
+
+func (s *subtitle) add(body []byte) error {
+   seg, err := new_vtt_segment(body)
+   if err != nil {
+      return err
+   }
+   var offset time.Duration
+   if seg.mpegts >= 0 {
+      if s.base < 0 {
+         s.base = seg.mpegts
+      }
+      delta := seg.mpegts - s.base
+      if delta < -mpegts_wrap / 2 {
+         delta += mpegts_wrap
+      }
+      offset = time.Duration(delta) * time.Second / mpegts_rate - seg.local
+   }
+   for _, item := range seg.cues {
+      item.start += offset
+      item.end += offset
+      s.add_cue(item)
+   }
+   return nil
+}
+
+// add_cue keeps a cue once. A cue that continues one ending where it
+// starts, as when a sample is split across segments, extends that cue.
+func (s *subtitle) add_cue(item cue) {
+   if s.seen[item] {
+      return
+   }
+   s.seen[item] = true
+   for i := len(s.cues) - 1; i >= 0; i-- {
+      last := &s.cues[i]
+      if last.end < item.start {
+         break
+      }
+      if last.end == item.start {
+         if last.text == item.text && last.settings == item.settings {
+            last.end = item.end
+            return
+         }
+      }
+   }
+   s.cues = append(s.cues, item)
+}
+
+func (s subtitle) sort() {
+   sort.SliceStable(s.cues, func(a, b int) bool {
+      return s.cues[a].start < s.cues[b].start
+   })
+}
+
+func (s subtitle) write_vtt(w io.Writer) error {
+   s.sort()
+   buf := bufio.NewWriter(w)
+   buf.WriteString("WEBVTT\n")
+   for _, item := range s.cues {
+      buf.WriteByte('\n')
+      buf.WriteString(format_timestamp(item.start, '.'))
+      buf.WriteString(" --> ")
+      buf.WriteString(format_timestamp(item.end, '.'))
+      if item.settings != "" {
+         buf.WriteByte(' ')
+         buf.WriteString(item.settings)
+      }
+      buf.WriteByte('\n')
+      buf.WriteString(item.text)
+      buf.WriteByte('\n')
+   }
+   return buf.Flush()
+}
+
+// srt_text keeps the b, i and u tags that SubRip understands, and drops
+// the others
+func srt_text(text string) string {
+   var b strings.Builder
+   for text != "" {
+      before, after, ok := strings.Cut(text, "<")
+      b.WriteString(html.UnescapeString(before))
+      if !ok {
+         break
+      }
+      tag, rest, ok := strings.Cut(after, ">")
+      if !ok {
+         break
+      }
+      switch strings.TrimPrefix(tag, "/") {
+      case "b", "i", "u":
+         b.WriteString("<" + tag + ">")
+      }
+      text = rest
+   }
+   return b.String()
+}
+
+func (s subtitle) write_srt(w io.Writer) error {
+   s.sort()
+   buf := bufio.NewWriter(w)
+   for i, item := range s.cues {
+      if i >= 1 {
+         buf.WriteByte('\n')
+      }
+      buf.WriteString(strconv.Itoa(i + 1))
+      buf.WriteByte('\n')
+      buf.WriteString(format_timestamp(item.start, ','))
+      buf.WriteString(" --> ")
+      buf.WriteString(format_timestamp(item.end, ','))
+      buf.WriteByte('\n')
+      buf.WriteString(srt_text(item.text))
+      buf.WriteByte('\n')
+   }
+   return buf.Flush()
+}
+
+// HLS_Subtitles downloads every WebVTT segment of a SUBTITLES rendition, and
+// writes them as one file. The file is SubRip if SRT is set.
+func (s Stream) HLS_Subtitles(items hls.Media, index int) error {
+   return s.HLS_Subtitles_Context(context.Background(), items, index)
+}
+
+func (s Stream) HLS_Subtitles_Context(
+   ctx context.Context, items hls.Media, index int,
+) error {
+   if s.Info {
+      return hls_get(ctx, s, items, index)
+   }
+   ref, err := s.base.Parse(items[index].URI())
+   if err != nil {
+      return err
+   }
+   play, base, err := s.get_playlist(ctx, ref)
+   if err != nil {
+      return err
+   }
+   var block *hls.Block
+   if play.key != "" {
+      key_ref, err := base.Parse(play.key)
+      if err != nil {
+         return err
+      }
+      block, err = s.get_key(ctx, key_ref)
+      if err != nil {
+         return err
+      }
+   }
+   sub := new_subtitle()
+   write := func(_ int, body []byte) error {
+      if block != nil {
+         body = block.Decrypt_Key(body)
+      }
+      return sub.add(body)
+   }
+   if err := s.fetch(ctx, base, play.segments, 0, write); err != nil {
+      return err
+   }
+   ext := ".vtt"
+   if s.SRT {
+      ext = ".srt"
+   }
+   file, err := s.sink().Create(s.path(ext))
+   if err != nil {
+      return err
+   }
+   defer file.Close()
+   if s.SRT {
+      err = sub.write_srt(file)
+   } else {
+      err = sub.write_vtt(file)
+   }
+   if err != nil {
+      return err
+   }
+   return file.Commit()
+}
@@ -0,0 +1,111 @@
+package mech
+
+import (
+   "github.com/89z/rosso/hls"
+   "net/http"
+   "net/http/httptest"
+   "net/url"
+   "os"
+   "testing"
+   "time"
+)
+
+// the second segment starts ten seconds later on the MPEG-TS clock, at
+// five seconds local time, and repeats the last cue of the first
+var vtt_segments = map[string]string{
+   "/subs.m3u8": "#EXTM3U\n#EXT-X-TARGETDURATION:10\n0.vtt\n1.vtt\n#EXT-X-ENDLIST\n",
+   "/0.vtt": `WEBVTT
+X-TIMESTAMP-MAP=MPEGTS:900000,LOCAL:00:00:00.000
+
+00:01.000 --> 00:02.500 line:90%
+<c.yellow>one</c> &amp; <i>two</i>
+
+1
+00:09.000 --> 00:11.000
+three
+`,
+   "/1.vtt": `WEBVTT
+X-TIMESTAMP-MAP=LOCAL:00:00:05.000,MPEGTS:1800000
+
+NOTE the cue below is also in 0.vtt
+
+00:04.000 --> 00:06.000
+three
+
+00:05.500 --> 00:06.000
+four
+`,
+}
+
+const vtt_want = `WEBVTT
+
+00:00:01.000 --> 00:00:02.500 line:90%
+<c.yellow>one</c> &amp; <i>two</i>
+
+00:00:09.000 --> 00:00:11.000
+three
+
+00:00:10.500 --> 00:00:11.000
+four
+`
+
+const srt_want = `1
+00:00:01,000 --> 00:00:02,500
+one & <i>two</i>
+
+2
+00:00:09,000 --> 00:00:11,000
+three
+
+3
+00:00:10,500 --> 00:00:11,000
+four
+`
+
+func Test_Subtitles(t *testing.T) {
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         w.Write([]byte(vtt_segments[r.URL.Path]))
+      },
+   ))
+   defer server.Close()
+   chdir(t)
+   base, err := url.Parse(server.URL)
+   if err != nil {
+      t.Fatal(err)
+   }
+   media := hls.Media{{Type: "SUBTITLES", Raw_URI: "subs.m3u8"}}
+   for ext, want := range map[string]string{".vtt": vtt_want, ".srt": srt_want} {
+      var str Stream
+      str.Name = "subs"
+      str.SRT = ext == ".srt"
+      str.base = base
+      if err := str.HLS_Subtitles(media, 0); err != nil {
+         t.Fatal(err)
+      }
+      text, err := os.ReadFile("subs" + ext)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if string(text) != want {
+         t.Fatal(string(text))
+      }
+   }
+}
+
+var timestamp_tests = map[string]time.Duration{
+   "00:01.000": time.Second,
+   "01:02:03.456": time.Hour + 2 * time.Minute + 3456 * time.Millisecond,
+}
+
+func Test_Timestamp(t *testing.T) {
+   for in, want := range timestamp_tests {
+      stamp, err := parse_timestamp(in)
+      if err != nil {
+         t.Fatal(err)
+      }
+      if stamp != want {
+         t.Fatal(in, stamp)
+      }
+   }
+}
@@ -2,6 +2,7 @@ package widevine
 
 import (
    "bytes"
+   "context"
    "crypto"
    "crypto/aes"
    "crypto/cipher"
@@ -21,6 +22,12 @@ type Poster interface {
 }
 
 func (m Module) Post(post Poster) (Containers, error) {
+   return m.Post_Context(context.Background(), post)
+}
+
+func (m Module) Post_Context(
+   ctx context.Context, post Poster,
+) (Containers, error) {
    signed_request, err := m.signed_request()
    if err != nil {
       return nil, err
@@ -38,7 +45,7 @@ func (m Module) Post(post Poster) (Containers, error) {
    if head := post.Request_Header(); head != nil {
       req.Header = head
    }
-   res, err := Client.Do(req)
+   res, err := Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
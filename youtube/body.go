@@ -2,6 +2,7 @@ package youtube
 
 import (
    "bytes"
+   "context"
    "encoding/json"
    "net/http"
 )
@@ -50,6 +51,12 @@ func Android_Content() Request {
 }
 
 func (r Request) Search(query string) (*Search, error) {
+   return r.Search_Context(context.Background(), query)
+}
+
+func (r Request) Search_Context(
+   ctx context.Context, query string,
+) (*Search, error) {
    filter := New_Filter()
    filter.Type(Type["Video"])
    param := New_Params()
@@ -61,13 +68,13 @@ func (r Request) Search(query string) (*Search, error) {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", origin + "/youtubei/v1/search", bytes.NewReader(buf),
+      "POST", Base.Origin + "/youtubei/v1/search", bytes.NewReader(buf),
    )
    if err != nil {
       return nil, err
    }
    req.Header.Set("X-Goog-API-Key", goog_API)
-   res, err := HTTP_Client.Do(req)
+   res, err := HTTP_Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
@@ -97,13 +104,19 @@ type Request struct {
 }
 
 func (r Request) Player(id string) (*Player, error) {
+   return r.Player_Context(context.Background(), id)
+}
+
+func (r Request) Player_Context(
+   ctx context.Context, id string,
+) (*Player, error) {
    r.body.Video_ID = id
    buf, err := json.MarshalIndent(r.body, "", " ")
    if err != nil {
       return nil, err
    }
    req, err := http.NewRequest(
-      "POST", origin + "/youtubei/v1/player", bytes.NewReader(buf),
+      "POST", Base.Origin + "/youtubei/v1/player", bytes.NewReader(buf),
    )
    if err != nil {
       return nil, err
@@ -113,7 +126,7 @@ func (r Request) Player(id string) (*Player, error) {
    } else {
       req.Header.Set("X-Goog-API-Key", goog_API)
    }
-   res, err := HTTP_Client.Do(req)
+   res, err := HTTP_Client.Do(req.WithContext(ctx))
    if err != nil {
       return nil, err
    }
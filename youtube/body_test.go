@@ -2,12 +2,14 @@ package youtube
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
    "os"
    "testing"
    "time"
 )
 
 func Test_Search(t *testing.T) {
+   cassette.Require(t)
    search, err := Mobile_Web().Search("oneohtrix point never along")
    if err != nil {
       t.Fatal(err)
@@ -20,6 +22,7 @@ func Test_Search(t *testing.T) {
 const android = "zv9NimPx3Es"
 
 func Test_Android(t *testing.T) {
+   cassette.Require(t)
    play, err := Android().Player(android)
    if err != nil {
       t.Fatal(err)
@@ -35,6 +38,7 @@ var android_embeds = []string{
 }
 
 func Test_Android_Embed(t *testing.T) {
+   cassette.Require(t)
    for _, embed := range android_embeds {
       play, err := Android_Embed().Player(embed)
       if err != nil {
@@ -56,6 +60,7 @@ var android_racys = []string{
 }
 
 func Test_Android_Racy(t *testing.T) {
+   cassette.Require(t)
    home, err := os.UserHomeDir()
    if err != nil {
       t.Fatal(err)
@@ -63,7 +68,7 @@ func Test_Android_Racy(t *testing.T) {
    req := Android_Racy()
    req.Header, err = Open_Header(home + "/mech/youtube.json")
    if err != nil {
-      t.Fatal(err)
+      t.Skip(err)
    }
    for _, racy := range android_racys {
       play, err := req.Player(racy)
@@ -80,6 +85,7 @@ func Test_Android_Racy(t *testing.T) {
 const android_content = "nGC3D_FkCmg"
 
 func Test_Android_Content(t *testing.T) {
+   cassette.Require(t)
    home, err := os.UserHomeDir()
    if err != nil {
       t.Fatal(err)
@@ -87,7 +93,7 @@ func Test_Android_Content(t *testing.T) {
    req := Android_Content()
    req.Header, err = Open_Header(home + "/mech/youtube.json")
    if err != nil {
-      t.Fatal(err)
+      t.Skip(err)
    }
    play, err := req.Player(android_content)
    if err != nil {
@@ -1,8 +1,12 @@
 package youtube
 
 import (
+   "context"
    "errors"
-   "github.com/89z/rosso/os"
+   "github.com/89z/mech/progress"
+   "github.com/89z/mech/rate"
+   "github.com/89z/mech/sink"
+   "github.com/89z/mech/verify"
    "io"
    "mime"
    "net/http"
@@ -59,31 +63,87 @@ func (f Format) MarshalText() ([]byte, error) {
 type Formats []Format
 
 func (f Format) Encode(w io.Writer) error {
-   req, err := http.NewRequest("GET", f.URL, nil)
+   return f.Encode_Context(context.Background(), w)
+}
+
+// Encode_Context stops when ctx is done. What w holds is then the start of
+// the format, with no gap.
+func (f Format) Encode_Context(ctx context.Context, w io.Writer) error {
+   var name string
+   if file, ok := w.(interface{ Name() string }); ok {
+      name = file.Name()
+   }
+   pro := progress.New_Bytes(w, Progress, name, f.ContentLength)
+   return pro.Finish(f.encode(ctx, pro))
+}
+
+// Encode_Sink writes the format to name in s. The output is only committed
+// if the whole format was written.
+func (f Format) Encode_Sink(s sink.Sink, name string) error {
+   return f.Encode_Sink_Context(context.Background(), s, name)
+}
+
+func (f Format) Encode_Sink_Context(
+   ctx context.Context, s sink.Sink, name string,
+) error {
+   file, err := s.Create(name)
    if err != nil {
       return err
    }
-   pro := os.Progress_Bytes(w, f.ContentLength)
-   var pos int64
+   defer file.Close()
+   pro := progress.New_Bytes(file, Progress, name, f.ContentLength)
+   if err := pro.Finish(f.encode(ctx, pro)); err != nil {
+      return err
+   }
+   return file.Commit()
+}
+
+// encode checks each chunk against the length it asked for, and the total
+// against ContentLength. A chunk cut short goes on from where it stopped,
+// up to Attempts times in a row.
+func (f Format) encode(ctx context.Context, pro *progress.Tracker) error {
+   req, err := http.NewRequestWithContext(ctx, "GET", f.URL, nil)
+   if err != nil {
+      return err
+   }
+   limit := rate.New_Writer(pro, rate.Global, rate.New_Limiter(Rate))
+   var (
+      attempt int
+      pos int64
+   )
    for pos < f.ContentLength {
+      end := pos + chunk
+      if end > f.ContentLength {
+         end = f.ContentLength
+      }
       b := []byte("bytes=")
       b = strconv.AppendInt(b, pos, 10)
       b = append(b, '-')
-      b = strconv.AppendInt(b, pos+chunk-1, 10)
+      b = strconv.AppendInt(b, end-1, 10)
       req.Header.Set("Range", string(b))
       res, err := HTTP_Client.Level(0).Redirect(nil).Status(206).Do(req)
       if err != nil {
          return err
       }
-      if _, err := io.Copy(pro, res.Body); err != nil {
+      n, err := io.Copy(limit, res.Body)
+      res.Body.Close()
+      pos += n
+      if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
          return err
       }
-      if err := res.Body.Close(); err != nil {
-         return err
+      if pos > end {
+         return verify.Length_Error{URL: f.URL, Want: end, Got: pos}
+      }
+      if pos < end {
+         attempt++
+         if attempt >= Attempts {
+            return verify.Length_Error{URL: f.URL, Want: end, Got: pos}
+         }
+         continue
       }
-      pos += chunk
+      attempt = 0
    }
-   return nil
+   return verify.Length(f.URL, f.ContentLength, pos)
 }
 
 func (f Formats) Audio(quality string) (*Format, bool) {
@@ -0,0 +1,44 @@
+package youtube
+
+import (
+   "bytes"
+   "net/http"
+   "net/http/httptest"
+   "strings"
+   "testing"
+   "time"
+)
+
+const format_body = "0123456789"
+
+
+func Test_Encode(t *testing.T) {
+   var requests []string
+   server := httptest.NewServer(http.HandlerFunc(
+      func(w http.ResponseWriter, r *http.Request) {
+         requests = append(requests, r.Header.Get("Range"))
+         if len(requests) == 1 {
+            // promise the whole range, then send less
+            w.Header().Set("Content-Length", "10")
+            w.WriteHeader(http.StatusPartialContent)
+            w.Write([]byte(format_body[:3]))
+            return
+         }
+         http.ServeContent(
+            w, r, "", time.Time{}, strings.NewReader(format_body),
+         )
+      },
+   ))
+   defer server.Close()
+   form := Format{ContentLength: 10, URL: server.URL}
+   var buf bytes.Buffer
+   if err := form.Encode(&buf); err != nil {
+      t.Fatal(err)
+   }
+   if buf.String() != format_body {
+      t.Fatal(buf.String())
+   }
+   if requests[1] != "bytes=3-9" {
+      t.Fatal(requests)
+   }
+}
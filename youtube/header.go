@@ -1,6 +1,7 @@
 package youtube
 
 import (
+   "context"
    "encoding/json"
    "github.com/89z/rosso/os"
    "net/http"
@@ -45,13 +46,17 @@ type Header struct {
 }
 
 func (h *Header) Refresh() error {
+   return h.Refresh_Context(context.Background())
+}
+
+func (h *Header) Refresh_Context(ctx context.Context) error {
    val := url.Values{
       "client_id": {client_ID},
       "client_secret": {client_secret},
       "grant_type": {"refresh_token"},
       "refresh_token": {h.Refresh_Token},
    }
-   res, err := http.PostForm("https://oauth2.googleapis.com/token", val)
+   res, err := post_form(ctx, Base.OAuth + "/token", val)
    if err != nil {
       return err
    }
@@ -59,6 +64,19 @@ func (h *Header) Refresh() error {
    return json.NewDecoder(res.Body).Decode(h)
 }
 
+func post_form(
+   ctx context.Context, ref string, val url.Values,
+) (*http.Response, error) {
+   req, err := http.NewRequestWithContext(
+      ctx, "POST", ref, strings.NewReader(val.Encode()),
+   )
+   if err != nil {
+      return nil, err
+   }
+   req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+   return HTTP_Client.Do(req)
+}
+
 type OAuth struct {
    Device_Code string
    User_Code string
@@ -66,11 +84,15 @@ type OAuth struct {
 }
 
 func New_OAuth() (*OAuth, error) {
+   return New_OAuth_Context(context.Background())
+}
+
+func New_OAuth_Context(ctx context.Context) (*OAuth, error) {
    val := url.Values{
       "client_id": {client_ID},
       "scope": {"https://www.googleapis.com/auth/youtube"},
    }
-   res, err := http.PostForm("https://oauth2.googleapis.com/device/code", val)
+   res, err := post_form(ctx, Base.OAuth + "/device/code", val)
    if err != nil {
       return nil, err
    }
@@ -83,13 +105,17 @@ func New_OAuth() (*OAuth, error) {
 }
 
 func (o OAuth) Header() (*Header, error) {
+   return o.Header_Context(context.Background())
+}
+
+func (o OAuth) Header_Context(ctx context.Context) (*Header, error) {
    val := url.Values{
       "client_id": {client_ID},
       "client_secret": {client_secret},
       "device_code": {o.Device_Code},
       "grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
    }
-   res, err := http.PostForm("https://oauth2.googleapis.com/token", val)
+   res, err := post_form(ctx, Base.OAuth + "/token", val)
    if err != nil {
       return nil, err
    }
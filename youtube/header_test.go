@@ -2,11 +2,13 @@ package youtube
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
    "testing"
    "time"
 )
 
 func Test_OAuth(t *testing.T) {
+   cassette.Require(t)
    auth, err := New_OAuth()
    if err != nil {
       t.Fatal(err)
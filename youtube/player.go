@@ -1,6 +1,7 @@
 package youtube
 
 import (
+   "github.com/89z/mech/meta"
    "strconv"
    "strings"
    "time"
@@ -78,6 +79,16 @@ func (p Player) Name() string {
    return buf.String()
 }
 
+func (p Player) Meta() meta.Data {
+   return meta.Data{
+      Author: p.VideoDetails.Author,
+      Date: p.PublishDate(),
+      ID: p.VideoDetails.VideoId,
+      Site: "youtube",
+      Title: p.VideoDetails.Title,
+   }
+}
+
 func (p Status) String() string {
    var buf strings.Builder
    buf.WriteString("Status: ")
@@ -1,12 +1,26 @@
 package youtube
 
 import (
+   "github.com/89z/mech/extractor"
+   "github.com/89z/mech/progress"
    "github.com/89z/rosso/http"
    "net/url"
    "path"
    "strings"
 )
 
+func init() {
+   extractor.Register(extractor.Extractor{
+      Name: "youtube",
+      Hosts: []string{"youtu.be", "youtube.com"},
+      ID: func(ref *url.URL) (string, error) {
+         var id string
+         err := Video_ID(ref.String(), &id)
+         return id, err
+      },
+   })
+}
+
 func Video_ID(data string, v *string) error {
    ref, err := url.Parse(data)
    if err != nil {
@@ -19,10 +33,29 @@ func Video_ID(data string, v *string) error {
    return nil
 }
 
-const origin = "https://www.youtube.com"
+// Base_URL is the origin of YouTube, and of Google for OAuth
+type Base_URL struct {
+   Origin string
+   OAuth string // device flow and tokens
+}
+
+var Base = Base_URL{
+   OAuth: "https://oauth2.googleapis.com",
+   Origin: "https://www.youtube.com",
+}
 
 var HTTP_Client = http.Default_Client
 
+// Rate limits each Format.Encode, in bytes per second. Zero is no limit.
+var Rate int64
+
+// Attempts is how many times in a row Format.Encode asks for a chunk that
+// came back short
+var Attempts = 3
+
+// Progress is told of each Format.Encode, nil for progress.Default
+var Progress progress.Observer
+
 type Image struct {
    Crop bool
    Height int
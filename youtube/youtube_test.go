@@ -2,11 +2,17 @@ package youtube
 
 import (
    "fmt"
+   "github.com/89z/mech/cassette"
    "net/http"
+   "os"
    "testing"
    "time"
 )
 
+func TestMain(m *testing.M) {
+   os.Exit(cassette.Main(m.Run, "testdata/youtube.json", &HTTP_Client))
+}
+
 var id_tests = []string{
    "https://youtube.com/shorts/9Vsdft81Q6w",
    "https://youtube.com/watch?v=XY-hOqcPGCY",
@@ -26,6 +32,7 @@ func Test_ID(t *testing.T) {
 const image_test = "UpNXI3_ctAc"
 
 func Test_Image(t *testing.T) {
+   cassette.Require(t)
    for _, img := range Images {
       ref := img.Address(image_test)
       fmt.Println("HEAD", ref)